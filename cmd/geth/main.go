@@ -44,6 +44,8 @@ import (
 
 const (
 	clientIdentifier = "geth" // Client identifier to advertise over the network
+
+	bootnodeCheckTimeout = 5 * time.Second // Time the bootnodes are given to answer the startup ping
 )
 
 var (
@@ -104,6 +106,10 @@ var (
 		utils.UltraLightOnlyAnnounceFlag,
 		utils.LightNoSyncServeFlag,
 		utils.WhitelistFlag,
+		utils.ValidationNonceWindowFlag,
+		utils.ValidationNonceRejectFlag,
+		utils.ValidationTimeoutFlag,
+		utils.ValidationDumpStateFlag,
 		utils.BloomFilterSizeFlag,
 		utils.TriesInMemoryFlag,
 		utils.CacheFlag,
@@ -154,6 +160,7 @@ var (
 		utils.EWASMInterpreterFlag,
 		utils.EVMInterpreterFlag,
 		utils.MinerNotifyFullFlag,
+		utils.MinerStratumFlag,
 		configFileFlag,
 		utils.CatalystFlag,
 	}
@@ -338,6 +345,9 @@ func startNode(ctx *cli.Context, stack *node.Node, backend ethapi.Backend) {
 	// Start up the node itself
 	utils.StartNode(ctx, stack)
 
+	// Make sure the bootnodes are reachable, a fresh node won't find peers otherwise
+	go checkBootnodes(stack)
+
 	// Unlock any account specifically requested
 	unlockAccounts(ctx, stack)
 
@@ -430,6 +440,31 @@ func startNode(ctx *cli.Context, stack *node.Node, backend ethapi.Backend) {
 	}
 }
 
+// checkBootnodes pings the configured bootnodes and warns if fewer than a quorum
+// of them answers.
+func checkBootnodes(stack *node.Node) {
+	cfg := stack.Config().P2P
+	if cfg.NoDiscovery || len(cfg.BootstrapNodes) == 0 {
+		return
+	}
+	urls := make([]string, len(cfg.BootstrapNodes))
+	for i, node := range cfg.BootstrapNodes {
+		urls[i] = node.URLv4()
+	}
+	var reachable int
+	for _, status := range utils.CheckBootnodes(urls, bootnodeCheckTimeout) {
+		if status.Reachable {
+			reachable++
+			log.Debug("Bootnode reachable", "url", status.URL, "ip", status.IP, "rtt", status.RTT)
+		} else {
+			log.Debug("Bootnode unreachable", "url", status.URL, "ip", status.IP, "err", status.Err)
+		}
+	}
+	if quorum := len(urls)/2 + 1; reachable < quorum {
+		log.Warn("Too few bootnodes reachable, peer discovery may fail", "reachable", reachable, "quorum", quorum, "total", len(urls))
+	}
+}
+
 // unlockAccounts unlocks any account specifically requested.
 func unlockAccounts(ctx *cli.Context, stack *node.Node) {
 	var unlocks []string
@@ -54,6 +54,10 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.IdentityFlag,
 			utils.LightKDFFlag,
 			utils.WhitelistFlag,
+			utils.ValidationNonceWindowFlag,
+			utils.ValidationNonceRejectFlag,
+			utils.ValidationTimeoutFlag,
+			utils.ValidationDumpStateFlag,
 			utils.TriesInMemoryFlag,
 			utils.PorFlag,
 			utils.PorChallengeCommitUrlFlag,
@@ -184,6 +188,7 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.MinerThreadsFlag,
 			utils.MinerNotifyFlag,
 			utils.MinerNotifyFullFlag,
+			utils.MinerStratumFlag,
 			utils.MinerGasPriceFlag,
 			utils.MinerGasTargetFlag,
 			utils.MinerGasLimitFlag,
@@ -0,0 +1,120 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"PureChain/crypto"
+	"PureChain/p2p/discover/v4wire"
+	"PureChain/p2p/enode"
+)
+
+// BootnodeStatus reports whether a bootnode answered a discovery ping.
+type BootnodeStatus struct {
+	URL       string        // Enode URL of the bootnode
+	IP        net.IP        // Address the URL resolved to (nil if resolution failed)
+	Reachable bool          // Whether the bootnode answered the ping in time
+	RTT       time.Duration // Round trip time of the ping (0 if unreachable)
+	Err       error         // Reason the bootnode is unreachable
+}
+
+// CheckBootnodes resolves the given bootnodes and pings each of them over the
+// discovery v4 protocol, waiting at most timeout for every answer. The checks
+// run concurrently and the statuses are returned in the order of the nodes.
+func CheckBootnodes(nodes []string, timeout time.Duration) []BootnodeStatus {
+	statuses := make([]BootnodeStatus, len(nodes))
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		for i, url := range nodes {
+			statuses[i] = BootnodeStatus{URL: url, Err: err}
+		}
+		return statuses
+	}
+	var wg sync.WaitGroup
+	for i, url := range nodes {
+		wg.Add(1)
+		go func(i int, url string) {
+			defer wg.Done()
+			statuses[i] = checkBootnode(url, key, timeout)
+		}(i, url)
+	}
+	wg.Wait()
+	return statuses
+}
+
+// checkBootnode pings a single bootnode from an ephemeral node key.
+func checkBootnode(url string, key *ecdsa.PrivateKey, timeout time.Duration) BootnodeStatus {
+	status := BootnodeStatus{URL: url}
+
+	node, err := enode.ParseV4(url)
+	if err != nil {
+		status.Err = err
+		return status
+	}
+	status.IP = node.IP()
+	if node.UDP() == 0 {
+		status.Err = errors.New("no discovery port")
+		return status
+	}
+	addr := &net.UDPAddr{IP: node.IP(), Port: node.UDP()}
+	conn, err := net.DialUDP("udp", nil, addr)
+	if err != nil {
+		status.Err = err
+		return status
+	}
+	defer conn.Close()
+
+	start := time.Now()
+	packet, hash, err := v4wire.Encode(key, &v4wire.Ping{
+		Version:    4,
+		From:       v4wire.NewEndpoint(conn.LocalAddr().(*net.UDPAddr), 0),
+		To:         v4wire.NewEndpoint(addr, uint16(node.TCP())),
+		Expiration: uint64(start.Add(20 * time.Second).Unix()),
+	})
+	if err != nil {
+		status.Err = err
+		return status
+	}
+	conn.SetDeadline(start.Add(timeout))
+	if _, err := conn.Write(packet); err != nil {
+		status.Err = err
+		return status
+	}
+	// Wait for the pong, skipping the pings the bootnode sends to verify us
+	buf := make([]byte, 1280)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			status.Err = err
+			return status
+		}
+		reply, from, _, err := v4wire.Decode(buf[:n])
+		if err != nil || from.ID() != node.ID() {
+			continue
+		}
+		if pong, ok := reply.(*v4wire.Pong); ok && bytes.Equal(pong.ReplyTok, hash) {
+			status.Reachable, status.RTT = true, time.Since(start)
+			return status
+		}
+	}
+}
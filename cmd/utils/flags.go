@@ -236,6 +236,22 @@ var (
 		Name:  "whitelist",
 		Usage: "Comma separated block number-to-hash mappings to enforce (<number>=<hash>)",
 	}
+	ValidationNonceWindowFlag = cli.Uint64Flag{
+		Name:  "validation.noncewindow",
+		Usage: "Number of recent blocks to flag reused block nonces in (0 = disabled)",
+	}
+	ValidationNonceRejectFlag = cli.BoolFlag{
+		Name:  "validation.noncereject",
+		Usage: "Reject blocks reusing a recent nonce instead of only logging them",
+	}
+	ValidationTimeoutFlag = cli.DurationFlag{
+		Name:  "validation.timeout",
+		Usage: "Maximum time to spend validating the state of a block before rejecting it (0 = unlimited)",
+	}
+	ValidationDumpStateFlag = cli.BoolFlag{
+		Name:  "validation.dumpstate",
+		Usage: "Log the transactions of blocks failing validation with a mismatching state root",
+	}
 	BloomFilterSizeFlag = cli.Uint64Flag{
 		Name:  "bloomfilter.size",
 		Usage: "Megabytes of memory allocated to bloom-filter for pruning",
@@ -443,6 +459,10 @@ var (
 		Name:  "miner.notify.full",
 		Usage: "Notify with pending block headers instead of work packages",
 	}
+	MinerStratumFlag = cli.StringFlag{
+		Name:  "miner.stratum",
+		Usage: "TCP listening address of the stratum server for remote miners (e.g. 0.0.0.0:8008)",
+	}
 	MinerGasTargetFlag = cli.Uint64Flag{
 		Name:  "miner.gastarget",
 		Usage: "Target gas floor for mined blocks",
@@ -871,7 +891,7 @@ func setNodeUserIdent(ctx *cli.Context, cfg *node.Config) {
 // setBootstrapNodes creates a list of bootstrap nodes from the command line
 // flags, reverting to pre-configured ones if none have been specified.
 func setBootstrapNodes(ctx *cli.Context, cfg *p2p.Config) {
-	urls := params.MainnetBootnodes
+	urls := params.MainnetNetworkConfig.Bootnodes
 	switch {
 	case ctx.GlobalIsSet(BootnodesFlag.Name):
 		urls = SplitAndTrim(ctx.GlobalString(BootnodesFlag.Name))
@@ -884,9 +904,9 @@ func setBootstrapNodes(ctx *cli.Context, cfg *p2p.Config) {
 	//case ctx.GlobalBool(YoloV3Flag.Name):
 	//	urls = params.YoloV3Bootnodes
 	case ctx.GlobalBool(TestnetFlag.Name):
-		urls = params.TestnetBootnodes
+		urls = params.TestnetNetworkConfig.Bootnodes
 	case ctx.GlobalBool(DevnetFlag.Name):
-		urls = params.DevnetBootnodes
+		urls = params.DevnetNetworkConfig.Bootnodes
 
 	case cfg.BootstrapNodes != nil:
 		return // already set, don't apply defaults.
@@ -906,9 +926,9 @@ func setBootstrapNodes(ctx *cli.Context, cfg *p2p.Config) {
 }
 
 // setBootstrapNodesV5 creates a list of bootstrap nodes from the command line
-// flags, reverting to pre-configured ones if none have been specified.
+// flags. No discovery v5 bootnodes are pre-configured for the InitVerse networks.
 func setBootstrapNodesV5(ctx *cli.Context, cfg *p2p.Config) {
-	urls := params.V5Bootnodes
+	var urls []string
 	switch {
 	case ctx.GlobalIsSet(BootnodesFlag.Name):
 		urls = SplitAndTrim(ctx.GlobalString(BootnodesFlag.Name))
@@ -1441,6 +1461,9 @@ func setMiner(ctx *cli.Context, cfg *miner.Config) {
 		cfg.Notify = strings.Split(ctx.GlobalString(MinerNotifyFlag.Name), ",")
 	}
 	cfg.NotifyFull = ctx.GlobalBool(MinerNotifyFullFlag.Name)
+	if ctx.GlobalIsSet(MinerStratumFlag.Name) {
+		cfg.StratumAddr = ctx.GlobalString(MinerStratumFlag.Name)
+	}
 	if ctx.GlobalIsSet(MinerExtraDataFlag.Name) {
 		cfg.ExtraData = []byte(ctx.GlobalString(MinerExtraDataFlag.Name))
 	}
@@ -1488,6 +1511,23 @@ func setWhitelist(ctx *cli.Context, cfg *ethconfig.Config) {
 	}
 }
 
+// setValidation configures the optional block validation checks from the command
+// line flags.
+func setValidation(ctx *cli.Context, cfg *ethconfig.Config) {
+	if ctx.GlobalIsSet(ValidationNonceWindowFlag.Name) {
+		cfg.NonceReuseWindow = ctx.GlobalUint64(ValidationNonceWindowFlag.Name)
+	}
+	if ctx.GlobalIsSet(ValidationNonceRejectFlag.Name) {
+		cfg.RejectNonceReuse = ctx.GlobalBool(ValidationNonceRejectFlag.Name)
+	}
+	if ctx.GlobalIsSet(ValidationTimeoutFlag.Name) {
+		cfg.ValidationTimeout = ctx.GlobalDuration(ValidationTimeoutFlag.Name)
+	}
+	if ctx.GlobalIsSet(ValidationDumpStateFlag.Name) {
+		cfg.DumpOnStateMismatch = ctx.GlobalBool(ValidationDumpStateFlag.Name)
+	}
+}
+
 // CheckExclusive verifies that only a single instance of the provided flags was
 // set by the user. Each flag might optionally be followed by a string type to
 // specialize it further.
@@ -1552,6 +1592,7 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	setEthash(ctx, cfg)
 	setMiner(ctx, &cfg.Miner)
 	setWhitelist(ctx, cfg)
+	setValidation(ctx, cfg)
 	setLes(ctx, cfg)
 
 	if ctx.GlobalBool(AddressTypeFlag.Name) {
@@ -1692,18 +1733,21 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 		}
 		cfg.Genesis = core.DefaultGenesisBlock()
 		SetDNSDiscoveryDefaults(cfg, params.MainnetGenesisHash)
+		setGasLimitDefaults(ctx, cfg, params.MainnetGenesisHash)
 	case ctx.GlobalBool(TestnetFlag.Name):
 		if !ctx.GlobalIsSet(NetworkIdFlag.Name) {
 			cfg.NetworkId = 772
 		}
 		cfg.Genesis = core.DefaultTestnetGenesisBlock()
 		SetDNSDiscoveryDefaults(cfg, params.TestnetGenesisHash)
+		setGasLimitDefaults(ctx, cfg, params.TestnetGenesisHash)
 	case ctx.GlobalBool(DevnetFlag.Name):
 		if !ctx.GlobalIsSet(NetworkIdFlag.Name) {
 			cfg.NetworkId = 1
 		}
 		cfg.Genesis = core.DefaultDevnetGenesisBlock()
 		SetDNSDiscoveryDefaults(cfg, params.DevnetGenesisHash)
+		setGasLimitDefaults(ctx, cfg, params.DevnetGenesisHash)
 
 	//case ctx.GlobalBool(RopstenFlag.Name):
 	//	if !ctx.GlobalIsSet(NetworkIdFlag.Name) {
@@ -1783,7 +1827,19 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	}
 }
 
-// SetDNSDiscoveryDefaults configures DNS discovery with the given URL if
+// setGasLimitDefaults configures the gas floor and ceil the miner aims for to the
+// ones of the given network, unless they were changed through flags or config.
+func setGasLimitDefaults(ctx *cli.Context, cfg *ethconfig.Config, genesis common.Hash) {
+	floor, ceil := params.GasLimitsFor(genesis)
+	if !ctx.GlobalIsSet(MinerGasTargetFlag.Name) && cfg.Miner.GasFloor == ethconfig.Defaults.Miner.GasFloor {
+		cfg.Miner.GasFloor = floor
+	}
+	if !ctx.GlobalIsSet(MinerGasLimitFlag.Name) && cfg.Miner.GasCeil == ethconfig.Defaults.Miner.GasCeil {
+		cfg.Miner.GasCeil = ceil
+	}
+}
+
+// SetDNSDiscoveryDefaults configures DNS discovery with the known URLs if
 // no URLs are set.
 func SetDNSDiscoveryDefaults(cfg *ethconfig.Config, genesis common.Hash) {
 	if cfg.EthDiscoveryURLs != nil {
@@ -1793,8 +1849,12 @@ func SetDNSDiscoveryDefaults(cfg *ethconfig.Config, genesis common.Hash) {
 	if cfg.SyncMode == downloader.LightSync {
 		protocol = "les"
 	}
-	if url := params.KnownDNSNetwork(genesis, protocol); url != "" {
-		cfg.EthDiscoveryURLs = []string{url}
+	network := params.NetworkConfigFor(genesis)
+	if network == nil {
+		return
+	}
+	if urls := network.DNSNetworks(protocol); len(urls) > 0 {
+		cfg.EthDiscoveryURLs = urls
 		cfg.SnapDiscoveryURLs = cfg.EthDiscoveryURLs
 	}
 }
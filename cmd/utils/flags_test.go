@@ -18,8 +18,13 @@
 package utils
 
 import (
+	"flag"
 	"reflect"
 	"testing"
+	"time"
+
+	"PureChain/eth/ethconfig"
+	"gopkg.in/urfave/cli.v1"
 )
 
 func Test_SplitTagsFlag(t *testing.T) {
@@ -62,3 +67,37 @@ func Test_SplitTagsFlag(t *testing.T) {
 		})
 	}
 }
+
+// Tests that the block validation flags end up in the eth config.
+func TestSetValidation(t *testing.T) {
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	for _, f := range []cli.Flag{
+		ValidationNonceWindowFlag,
+		ValidationNonceRejectFlag,
+		ValidationTimeoutFlag,
+		ValidationDumpStateFlag,
+	} {
+		f.Apply(set)
+	}
+	args := []string{
+		"--validation.noncewindow=16",
+		"--validation.noncereject",
+		"--validation.timeout=3s",
+		"--validation.dumpstate",
+	}
+	if err := set.Parse(args); err != nil {
+		t.Fatalf("failed to parse flags: %v", err)
+	}
+	var cfg ethconfig.Config
+	setValidation(cli.NewContext(nil, set, nil), &cfg)
+
+	want := ethconfig.Config{
+		NonceReuseWindow:    16,
+		RejectNonceReuse:    true,
+		ValidationTimeout:   3 * time.Second,
+		DumpOnStateMismatch: true,
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("config mismatch: have %+v, want %+v", cfg, want)
+	}
+}
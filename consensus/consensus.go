@@ -134,6 +134,15 @@ type PoW interface {
 	Hashrate() float64
 }
 
+// Faker is a proof-of-work engine which may run in a fake mode for testing.
+type Faker interface {
+	PoW
+
+	// FullFake reports whether the engine accepts all headers without verifying
+	// them, allowing tests to import blocks with made up fields.
+	FullFake() bool
+}
+
 type PoSA interface {
 	Engine
 	//heco
@@ -643,6 +643,12 @@ func (ethash *Ethash) SetThreads(threads int) {
 	}
 }
 
+// FullFake implements consensus.Faker, reporting whether the engine runs in the
+// full fake mode, accepting all headers without verifying them.
+func (ethash *Ethash) FullFake() bool {
+	return ethash.config.PowMode == ModeFullFake
+}
+
 // Hashrate implements PoW, returning the measured rate of the search invocations
 // per second over the last minute.
 // Note the returned hashrate includes local hashrate, but also includes the total
@@ -18,52 +18,288 @@ package inihash
 
 import (
 	"PureChain/common"
+	"PureChain/common/gopool"
 	"PureChain/common/hexutil"
+	"PureChain/consensus"
+	"PureChain/core"
 	"PureChain/core/types"
+	"PureChain/event"
+	"PureChain/params"
+	"PureChain/rpc"
+	"context"
 	"errors"
+	"fmt"
+	"math/big"
+	"runtime"
+	"sync"
+	"time"
 )
 
-var errEthashStopped = errors.New("inihash stopped")
+// ErrSealerClosing is returned by the API if the engine is shutting down, telling
+// remote miners to retry with another node rather than report a failure.
+var ErrSealerClosing = errors.New("sealer closing")
+
+var errEthashStopped = fmt.Errorf("inihash stopped: %w", ErrSealerClosing)
 
 // API exposes inihash related methods for the RPC interface.
 type API struct {
+	chain   consensus.ChainHeaderReader
 	inihash *Inihash
 }
 
 // GetWork returns a work package for external miner.
 //
-// The work package consists of 3 strings:
+// The work package consists of 5 strings:
+//
+//	result[0] - 32 bytes hex encoded current block header pow-hash
+//	result[1] - 32 bytes hex encoded boundary condition ("target"), 2^256/difficulty
+//	result[2] - hex encoded block number
+//	result[3] - hex encoded block timestamp
+//	result[4] - mining algorithm, see ParseMiningAlgo
 //
-//		result[0] - 32 bytes hex encoded current block header pow-hash
-//		result[1] - 32 bytes hex encoded seed hash used for DAG
-//		result[2] - 32 bytes hex encoded boundary condition ("target"), 2^256/difficulty
-//		result[3] - hex encoded block number
-//	 result[4] - algo
-func (api *API) GetWork() ([4]string, error) {
+// If the sealer doesn't hand out the work within the configured deadline, an
+// error is returned instead of blocking the caller.
+func (api *API) GetWork() ([5]string, error) {
 	if api.inihash.remote == nil {
-		return [4]string{}, errors.New("not supported")
+		return [5]string{}, errors.New("not supported")
 	}
 
 	var (
 		workCh = make(chan [4]string, 1)
 		errc   = make(chan error, 1)
 	)
+	// Don't tie up the caller if the sealer is wedged, the buffered channels let
+	// a late answer go through without blocking the sealer
+	var deadline <-chan time.Time
+	if timeout := api.inihash.remote.workTimeout(); timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
 	select {
 	case api.inihash.remote.fetchWorkCh <- &sealWork{errc: errc, res: workCh}:
 	case <-api.inihash.remote.exitCh:
-		return [4]string{}, errEthashStopped
+		return [5]string{}, errEthashStopped
+	case <-deadline:
+		return [5]string{}, errWorkTimeout
 	}
 	select {
 	case work := <-workCh:
-		return work, nil
+		return [5]string{work[0], work[1], work[2], work[3], miningAlgo.String()}, nil
 	case err := <-errc:
+		return [5]string{}, err
+	case <-deadline:
+		return [5]string{}, errWorkTimeout
+	}
+}
+
+// WorkJSON is the current work package with self-describing fields, all of them
+// hex encoded like the entries of the GetWork array.
+type WorkJSON struct {
+	HeaderHash string `json:"headerHash"` // Pow-hash of the current block header
+	SeedHash   string `json:"seedHash"`   // Seed hash of the block's epoch
+	Target     string `json:"target"`     // Boundary condition, 2^256/difficulty
+	Number     string `json:"number"`     // Number of the block being sealed
+	Algo       string `json:"algo"`       // Mining algorithm, see ParseMiningAlgo
+}
+
+// GetWorkJSON returns the same work package as GetWork, but as named fields
+// rather than positional ones. GetWork stays for legacy miners.
+func (api *API) GetWorkJSON() (*WorkJSON, error) {
+	work, err := api.GetWork()
+	if err != nil {
+		return nil, err
+	}
+	number, err := hexutil.DecodeUint64(work[2])
+	if err != nil {
+		return nil, err
+	}
+	return &WorkJSON{
+		HeaderHash: work[0],
+		SeedHash:   common.BytesToHash(SeedHash(number)).Hex(),
+		Target:     work[1],
+		Number:     work[2],
+		Algo:       work[4],
+	}, nil
+}
+
+// GetWorkDetailed returns the current work package along with the details GetWork
+// leaves out: the seed hash, the mining algorithm, the block timestamp and whether
+// the work starts a new job, in which case miners should drop their in-flight
+// solutions, or merely refreshes the previous one.
+func (api *API) GetWorkDetailed() (*WorkDetails, error) {
+	if api.inihash.remote == nil {
+		return nil, errors.New("not supported")
+	}
+	if api.inihash.Paused() {
+		return nil, errMiningPaused
+	}
+	var res = make(chan *WorkDetails, 1)
+	select {
+	case api.inihash.remote.fetchDetlCh <- res:
+	case <-api.inihash.remote.exitCh:
+		return nil, errEthashStopped
+	}
+	if work := <-res; work != nil {
+		return work, nil
+	}
+	return nil, errNoMiningWork
+}
+
+// GetMiningDifficulty returns the difficulty of the block the current work package
+// seals, sparing miners from decoding it from the target.
+func (api *API) GetMiningDifficulty() (*hexutil.Big, error) {
+	if api.inihash.remote == nil {
+		return nil, errors.New("not supported")
+	}
+	block, err := api.sealingBlock()
+	if err != nil {
+		return nil, err
+	}
+	return (*hexutil.Big)(new(big.Int).Set(block.Difficulty())), nil
+}
+
+// GetTarget returns the 32 byte boundary condition of the current work package,
+// 2^256/difficulty, which a solution's result must not exceed.
+func (api *API) GetTarget() (hexutil.Bytes, error) {
+	if api.inihash.remote == nil {
+		return nil, errors.New("not supported")
+	}
+	block, err := api.sealingBlock()
+	if err != nil {
+		return nil, err
+	}
+	target := common.BytesToHash(new(big.Int).Div(two256, block.Difficulty()).Bytes())
+	return target[:], nil
+}
+
+// sealingBlock retrieves the block the current work package seals from the remote
+// sealer.
+func (api *API) sealingBlock() (*types.Block, error) {
+	var res = make(chan *types.Block, 1)
+	select {
+	case api.inihash.remote.fetchBlockCh <- res:
+	case <-api.inihash.remote.exitCh:
+		return nil, errEthashStopped
+	}
+	if block := <-res; block != nil {
+		return block, nil
+	}
+	return nil, errNoMiningWork
+}
+
+// GetRecentWork returns up to the n most recent work packages handed out to the
+// miners, newest first, letting proxies map late submissions back to their jobs.
+// At most 64 packages are retained.
+func (api *API) GetRecentWork(n int) ([]WorkDetails, error) {
+	if api.inihash.remote == nil {
+		return nil, errors.New("not supported")
+	}
+	if n <= 0 {
+		return nil, fmt.Errorf("invalid work count %d", n)
+	}
+	var res = make(chan []WorkDetails, 1)
+	select {
+	case api.inihash.remote.fetchRecCh <- res:
+	case <-api.inihash.remote.exitCh:
+		return nil, errEthashStopped
+	}
+	works := <-res
+	if len(works) > n {
+		works = works[:n]
+	}
+	return works, nil
+}
+
+// GetWorkForNumber returns the current work package as if it sealed the block with
+// the given number, carrying the seed hash of that block's epoch. It lets tests
+// exercise epoch transitions without mining up to them, so it's only enabled if
+// the engine runs in test or fake mode. The package is laid out like the one of
+// GetWorkDetailed, with the number and seed hash of the requested block.
+func (api *API) GetWorkForNumber(number uint64) (*WorkDetails, error) {
+	switch api.inihash.config.PowMode {
+	case ModeTest, ModeFake, ModeFullFake:
+	default:
+		return nil, errors.New("not supported")
+	}
+	work, err := api.GetWorkDetailed()
+	if err != nil {
+		return nil, err
+	}
+	work.SeedHash = common.BytesToHash(SeedHash(number))
+	work.Number = hexutil.Uint64(number)
+	return work, nil
+}
+
+// GetSubmitStats returns how many of the solutions submitted by remote miners were
+// accepted, and how many were rejected as stale, duplicate or invalid.
+func (api *API) GetSubmitStats() (SubmitStats, error) {
+	if api.inihash.remote == nil {
+		return SubmitStats{}, errors.New("not supported")
+	}
+	var res = make(chan SubmitStats, 1)
+	select {
+	case api.inihash.remote.fetchStatCh <- res:
+	case <-api.inihash.remote.exitCh:
+		return SubmitStats{}, errEthashStopped
+	}
+	return <-res, nil
+}
+
+// GetWorkCompat returns the current work package laid out the way stock ethash
+// miners expect it from eth_getWork:
+//
+//	result[0] - 32 bytes hex encoded current block header pow-hash
+//	result[1] - 32 bytes hex encoded seed hash
+//	result[2] - 32 bytes hex encoded boundary condition ("target"), 2^256/difficulty
+//	result[3] - hex encoded block number
+//
+// Unlike GetWork, the block timestamp is omitted so that off-the-shelf mining
+// software can connect without patches.
+func (api *API) GetWorkCompat() ([4]string, error) {
+	work, err := api.GetWork()
+	if err != nil {
 		return [4]string{}, err
 	}
+	number, err := hexutil.DecodeUint64(work[2])
+	if err != nil {
+		return [4]string{}, err
+	}
+	return [4]string{work[0], common.BytesToHash(SeedHash(number)).Hex(), work[1], work[2]}, nil
+}
+
+// EpochInfo describes the epoch of the block currently being mined.
+type EpochInfo struct {
+	Epoch      hexutil.Uint64 `json:"epoch"`
+	SeedHash   common.Hash    `json:"seedHash"`
+	StartBlock hexutil.Uint64 `json:"startBlock"`
+	EndBlock   hexutil.Uint64 `json:"endBlock"`
+	DAGCached  *bool          `json:"dagCached,omitempty"` // Whether the epoch's DAG is cached, nil if sealing uses none
+}
+
+// GetCurrentEpochInfo returns the epoch of the block following the chain head,
+// which is the block the work handed out to the miners is built for.
+//
+// Sealing with versaHash doesn't rely on a mining dataset, so there's no DAG to
+// report the state of.
+func (api *API) GetCurrentEpochInfo() (*EpochInfo, error) {
+	if api.chain == nil {
+		return nil, errors.New("not supported")
+	}
+	number := api.chain.CurrentHeader().Number.Uint64() + 1
+	epoch := number / epochLength
+	return &EpochInfo{
+		Epoch:      hexutil.Uint64(epoch),
+		SeedHash:   common.BytesToHash(SeedHash(number)),
+		StartBlock: hexutil.Uint64(epoch * epochLength),
+		EndBlock:   hexutil.Uint64((epoch+1)*epochLength - 1),
+	}, nil
 }
 
 // SubmitWork can be used by external miner to submit their POW solution.
 // It returns an indication if the work was accepted.
 // Note either an invalid solution, a stale work a non-existent work will return false.
+// Resubmitting an already accepted solution returns true without sealing it again.
 func (api *API) SubmitWork(nonce types.BlockNonce, extraNonce types.BlockNonce, hash common.Hash) bool {
 	if api.inihash.remote == nil {
 		return false
@@ -84,16 +320,45 @@ func (api *API) SubmitWork(nonce types.BlockNonce, extraNonce types.BlockNonce,
 	return err == nil
 }
 
+// SubmitWorkWithCoinbase is like SubmitWork, but also declares the coinbase the
+// external miner mined for. As the coinbase is part of the sealed work, solutions
+// declaring a different one than the work's are rejected, allowing pools fronting
+// several miners to attribute the blocks found.
+func (api *API) SubmitWorkWithCoinbase(nonce types.BlockNonce, extraNonce types.BlockNonce, hash common.Hash, coinbase common.Address) bool {
+	if api.inihash.remote == nil {
+		return false
+	}
+
+	var errc = make(chan error, 1)
+	select {
+	case api.inihash.remote.submitWorkCh <- &mineResult{
+		nonce:      nonce,
+		extraNonce: extraNonce,
+		hash:       hash,
+		coinbase:   &coinbase,
+		errc:       errc,
+	}:
+	case <-api.inihash.remote.exitCh:
+		return false
+	}
+	err := <-errc
+	return err == nil
+}
+
 // SubmitHashrate can be used for remote miners to submit their hash rate.
 // This enables the node to report the combined hash rate of all miners
 // which submit work through this node.
 //
 // It accepts the miner hash rate and an identifier which must be unique
-// between nodes.
+// between nodes. Rates above the configured ceiling are rejected as bogus.
 func (api *API) SubmitHashrate(rate hexutil.Uint64, id common.Hash) bool {
 	if api.inihash.remote == nil {
 		return false
 	}
+	if limit := api.inihash.remote.maxHashrate(); uint64(rate) > limit {
+		api.inihash.config.Log.Warn("Rejected implausible hash rate", "id", id, "rate", uint64(rate), "limit", limit)
+		return false
+	}
 
 	var done = make(chan struct{}, 1)
 	select {
@@ -107,11 +372,336 @@ func (api *API) SubmitHashrate(rate hexutil.Uint64, id common.Hash) bool {
 	return true
 }
 
+// GetSharesPerSecond returns the rate of valid, non-duplicate solutions submitted
+// by remote miners, averaged over the configured window. It serves as a liveness
+// signal of the miners independent of their reported hashrate.
+func (api *API) GetSharesPerSecond() (float64, error) {
+	if api.inihash.remote == nil {
+		return 0, errors.New("not supported")
+	}
+	var res = make(chan float64, 1)
+	select {
+	case api.inihash.remote.fetchShareCh <- res:
+	case <-api.inihash.remote.exitCh:
+		return 0, errEthashStopped
+	}
+	return <-res, nil
+}
+
+// WorkVerification is a single proof-of-work solution to be checked offline
+// by VerifyWorkBatch.
+type WorkVerification struct {
+	Header     *types.Header    `json:"header"`
+	Nonce      types.BlockNonce `json:"nonce"`
+	ExtraNonce types.BlockNonce `json:"extraNonce"`
+	MixDigest  common.Hash      `json:"mixDigest"`
+	Difficulty *hexutil.Big     `json:"difficulty"`
+}
+
+// VerifyHeaderPoW checks whether the given header carries a valid proof-of-work
+// seal without touching the chain database.
+func (api *API) VerifyHeaderPoW(header *types.Header) bool {
+	return api.inihash.VerifyHeaderPoW(header) == nil
+}
+
+// maxWorkBatch is the maximum number of solutions VerifyWorkBatch checks in one
+// call, bounding the work a single request can cause.
+const maxWorkBatch = 1024
+
+// VerifyWorkBatch checks a batch of proof-of-work solutions without touching the
+// chain database, e.g. to validate pool shares at scale. The solutions are
+// verified concurrently and the results are aligned with the items by index.
+//
+// If an item specifies a difficulty, the seal is checked against it instead of
+// the difficulty of the header.
+//
+// At most 1024 solutions are checked per call, larger batches are rejected with
+// an error without verifying any of them and need to be split by the caller.
+func (api *API) VerifyWorkBatch(items []WorkVerification) ([]bool, error) {
+	if len(items) > maxWorkBatch {
+		return nil, fmt.Errorf("batch too large: %d solutions, max %d", len(items), maxWorkBatch)
+	}
+	results := make([]bool, len(items))
+	if len(items) == 0 {
+		return results, nil
+	}
+	// Spawn as many workers as allowed threads
+	workers := runtime.GOMAXPROCS(0)
+	if len(items) < workers {
+		workers = len(items)
+	}
+	var (
+		inputs = make(chan int)
+		pend   sync.WaitGroup
+	)
+	for i := 0; i < workers; i++ {
+		pend.Add(1)
+		gopool.Submit(func() {
+			defer pend.Done()
+			for index := range inputs {
+				results[index] = api.verifyWork(&items[index])
+			}
+		})
+	}
+	for i := range items {
+		inputs <- i
+	}
+	close(inputs)
+	pend.Wait()
+
+	return results, nil
+}
+
+// verifyWork assembles the header sealed by the given solution and checks its
+// proof-of-work via the offline verification path.
+func (api *API) verifyWork(item *WorkVerification) bool {
+	if item.Header == nil {
+		return false
+	}
+	header := types.CopyHeader(item.Header)
+	header.Nonce = item.Nonce
+	header.ExtraNonce = item.ExtraNonce
+	header.MixDigest = item.MixDigest
+	if item.Difficulty != nil {
+		header.Difficulty = new(big.Int).Set(item.Difficulty.ToInt())
+	}
+	return api.inihash.VerifyHeaderPoW(header) == nil
+}
+
+// ListPendingWork returns the work packages the remote sealer still accepts
+// submissions for, along with their block numbers and ages.
+func (api *API) ListPendingWork() ([]PendingWork, error) {
+	if api.inihash.remote == nil {
+		return nil, errors.New("not supported")
+	}
+	var res = make(chan []PendingWork, 1)
+	select {
+	case api.inihash.remote.fetchPendCh <- res:
+	case <-api.inihash.remote.exitCh:
+		return nil, errEthashStopped
+	}
+	return <-res, nil
+}
+
+// GetMiningRole returns whether the node is mining solo with its local CPU miner,
+// only serving work to remote miners ("pool"), or not mining at all.
+func (api *API) GetMiningRole() string {
+	return api.inihash.MiningRole()
+}
+
+// GetNextDifficulty returns the difficulty of a block mined on top of the current
+// head right now, as computed by the engine's difficulty calculator.
+func (api *API) GetNextDifficulty() (*big.Int, error) {
+	if api.chain == nil {
+		return nil, errors.New("not supported")
+	}
+	head := api.chain.CurrentHeader()
+	if head == nil {
+		return nil, errors.New("no current head")
+	}
+	// A child block is at least one second younger than its parent
+	now := uint64(time.Now().Unix())
+	if now <= head.Time {
+		now = head.Time + 1
+	}
+	return api.inihash.CalcDifficulty(api.chain, now, head), nil
+}
+
+// maxDifficultyHistory is the maximum number of blocks GetDifficultyHistory
+// returns the difficulty of in one call.
+const maxDifficultyHistory = 1024
+
+// GetDifficultyHistory returns the difficulty of each canonical block in the
+// given inclusive range, e.g. to chart its evolution.
+func (api *API) GetDifficultyHistory(fromBlock, toBlock uint64) ([]*big.Int, error) {
+	if api.chain == nil {
+		return nil, errors.New("not supported")
+	}
+	if fromBlock > toBlock {
+		return nil, fmt.Errorf("invalid range: from %d > to %d", fromBlock, toBlock)
+	}
+	if toBlock-fromBlock >= maxDifficultyHistory {
+		return nil, fmt.Errorf("range too large: %d blocks, max %d", toBlock-fromBlock+1, maxDifficultyHistory)
+	}
+	if head := api.chain.CurrentHeader().Number.Uint64(); toBlock > head {
+		return nil, fmt.Errorf("block %d beyond head %d", toBlock, head)
+	}
+	history := make([]*big.Int, 0, toBlock-fromBlock+1)
+	for number := fromBlock; number <= toBlock; number++ {
+		header := api.chain.GetHeaderByNumber(number)
+		if header == nil {
+			return nil, fmt.Errorf("block %d not found", number)
+		}
+		history = append(history, new(big.Int).Set(header.Difficulty))
+	}
+	return history, nil
+}
+
+// GetTargetBlockTime returns the number of seconds between blocks the network's
+// difficulty adjustment aims for.
+func (api *API) GetTargetBlockTime() (uint64, error) {
+	if api.chain == nil {
+		return 0, errors.New("not supported")
+	}
+	return TargetBlockTime(api.chain.Config())
+}
+
+// MinedBlock is the notification sent to miners when a block sealed from one of
+// their submissions becomes part of the canonical chain.
+type MinedBlock struct {
+	Hash   common.Hash    `json:"hash"`
+	Number hexutil.Uint64 `json:"number"`
+}
+
+// chainEventSubscriber is implemented by chains able to report newly inserted
+// canonical blocks.
+type chainEventSubscriber interface {
+	SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription
+}
+
+// MinedBlock creates a subscription notifying remote miners whenever a block
+// sealed from a solution submitted through SubmitWork is accepted into the
+// canonical chain.
+func (api *API) MinedBlock(ctx context.Context) (*rpc.Subscription, error) {
+	if api.inihash.remote == nil {
+		return nil, errors.New("not supported")
+	}
+	chain, ok := api.chain.(chainEventSubscriber)
+	if !ok {
+		return nil, errors.New("not supported")
+	}
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	gopool.Submit(func() {
+		events := make(chan core.ChainEvent, 16)
+		sub := chain.SubscribeChainEvent(events)
+		defer sub.Unsubscribe()
+
+		for {
+			select {
+			case ev := <-events:
+				if api.inihash.remote.isSubmitted(ev.Hash) {
+					notifier.Notify(rpcSub.ID, &MinedBlock{Hash: ev.Hash, Number: hexutil.Uint64(ev.Block.NumberU64())})
+				}
+			case <-sub.Err():
+				return
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	})
+	return rpcSub, nil
+}
+
+// WorkReward is the estimated payout for solving the current work package.
+type WorkReward struct {
+	Hash        common.Hash    `json:"hash"`        // Seal hash of the work package
+	Number      hexutil.Uint64 `json:"number"`      // Number of the block being sealed
+	Coinbase    common.Address `json:"coinbase"`    // Address receiving the rewards
+	BlockReward *hexutil.Big   `json:"blockReward"` // Static block reward
+	Fees        *hexutil.Big   `json:"fees"`        // Estimated transaction fees
+	UncleBonus  *hexutil.Big   `json:"uncleBonus"`  // Reward for including uncles
+	Total       *hexutil.Big   `json:"total"`       // Sum of all the above
+}
+
+// GetWorkReward returns the coinbase of the current work package and an estimate
+// of the reward for solving it.
+//
+// The work doesn't carry the receipts of its transactions, so the fees are
+// estimated by pricing the block's gas used at the average gas price of its
+// transactions, weighted by their gas limits. The estimate is exact if all the
+// transactions pay the same price.
+func (api *API) GetWorkReward() (*WorkReward, error) {
+	if api.inihash.remote == nil || api.chain == nil {
+		return nil, errors.New("not supported")
+	}
+	block, err := api.sealingBlock()
+	if err != nil {
+		return nil, err
+	}
+	header := block.Header()
+
+	blockReward := new(big.Int)
+	if !api.chain.Config().IsCatalyst(header.Number) {
+		blockReward = blockRewardAt(api.chain.Config(), header.Number)
+	}
+	bonus := new(big.Int).Div(blockReward, big32)
+	bonus.Mul(bonus, big.NewInt(int64(len(block.Uncles()))))
+
+	var (
+		fees  = new(big.Int)
+		limit = new(big.Int)
+	)
+	for _, tx := range block.Transactions() {
+		gas := new(big.Int).SetUint64(tx.Gas())
+		fees.Add(fees, gas.Mul(gas, tx.GasPrice()))
+		limit.Add(limit, new(big.Int).SetUint64(tx.Gas()))
+	}
+	if limit.Sign() > 0 {
+		fees.Mul(fees, new(big.Int).SetUint64(header.GasUsed))
+		fees.Div(fees, limit)
+	}
+	total := new(big.Int).Add(blockReward, fees)
+	total.Add(total, bonus)
+
+	return &WorkReward{
+		Hash:        api.inihash.SealHash(header),
+		Number:      hexutil.Uint64(header.Number.Uint64()),
+		Coinbase:    header.Coinbase,
+		BlockReward: (*hexutil.Big)(blockReward),
+		Fees:        (*hexutil.Big)(fees),
+		UncleBonus:  (*hexutil.Big)(bonus),
+		Total:       (*hexutil.Big)(total),
+	}, nil
+}
+
+// GetHashrateByID returns the last hash rate reported by each remote miner, keyed
+// by the id it submitted the rate with. Miners that didn't report recently are
+// omitted.
+func (api *API) GetHashrateByID() (map[common.Hash]uint64, error) {
+	if api.inihash.remote == nil {
+		return nil, errors.New("not supported")
+	}
+	var res = make(chan map[common.Hash]uint64, 1)
+	select {
+	case api.inihash.remote.fetchRatesCh <- res:
+	case <-api.inihash.remote.exitCh:
+		return nil, errEthashStopped
+	}
+	return <-res, nil
+}
+
 // GetHashrate returns the current hashrate for local CPU miner and remote miner.
 func (api *API) GetHashrate() uint64 {
 	return uint64(api.inihash.Hashrate())
 }
 
+// GetHashrateHistory returns up to the given number of most recent samples of the
+// combined local and remote hash rate, oldest first. Samples are taken every 5
+// seconds and at most 720 of them are retained, an hour's worth.
+func (api *API) GetHashrateHistory(samples int) []HashratePoint {
+	if api.inihash.remote == nil || samples <= 0 {
+		return nil
+	}
+	var res = make(chan []HashratePoint, 1)
+	select {
+	case api.inihash.remote.fetchHistCh <- res:
+	case <-api.inihash.remote.exitCh:
+		return nil
+	}
+	history := <-res
+	if len(history) > samples {
+		history = history[len(history)-samples:]
+	}
+	return history
+}
+
 // GetHashrate returns the current hashrate for local CPU miner and remote miner.
 func (api *API) GetBlockReward(number hexutil.Uint64) string {
 	realBlockReward := api.inihash.GetBlockReward(uint64(number))
@@ -119,3 +709,32 @@ func (api *API) GetBlockReward(number hexutil.Uint64) string {
 	//realBlockReward.Div(realBlockReward, big10)
 	return "0x" + realBlockReward.Text(16)
 }
+
+// PrivateMinerAPI exposes the inihash mining controls reserved to the node
+// operator. It is served on the private miner namespace only.
+type PrivateMinerAPI struct {
+	chain   consensus.ChainHeaderReader
+	inihash *Inihash
+}
+
+// PauseMining temporarily stops sealing, e.g. during maintenance. The local CPU
+// miner stops and GetWork reports that mining is paused instead of handing out
+// work, until mining is resumed.
+func (api *PrivateMinerAPI) PauseMining() {
+	api.inihash.Pause()
+}
+
+// ResumeMining restarts sealing paused by PauseMining.
+func (api *PrivateMinerAPI) ResumeMining() {
+	api.inihash.Resume()
+}
+
+// SetExtraData sets the extra-data, e.g. a vanity tag, of the blocks mined from
+// now on. Work handed out after the next work refresh carries the new value.
+func (api *PrivateMinerAPI) SetExtraData(extra hexutil.Bytes) error {
+	var config *params.ChainConfig
+	if api.chain != nil {
+		config = api.chain.Config()
+	}
+	return api.inihash.SetExtraData(config, extra)
+}
@@ -60,6 +60,8 @@ var (
 	errInvalidDifficulty = errors.New("non-positive difficulty")
 	errInvalidMixDigest  = errors.New("invalid mix digest")
 	errInvalidPoW        = errors.New("invalid proof-of-work")
+
+	errNoTargetBlockTime = errors.New("network doesn't define a target block time")
 )
 
 // Author implements consensus.Engine, returning the header's coinbase as the
@@ -190,6 +192,9 @@ func (inihash *Inihash) VerifyUncles(chain consensus.ChainReader, block *types.B
 	if len(block.Uncles()) == 0 {
 		return nil
 	}
+	if err := verifyUncleRewards(chain.Config(), block); err != nil {
+		return err
+	}
 	// Gather the set of past uncles and ancestors
 	uncles, ancestors := mapset.NewSet(), make(map[common.Hash]*types.Header)
 
@@ -314,10 +319,41 @@ func (inihash *Inihash) CalcDifficulty(chain consensus.ChainHeaderReader, time u
 // given the parent block's time and difficulty.
 func CalcDifficulty(config *params.ChainConfig, time uint64, parent *types.Header) *big.Int {
 	//next := new(big.Int).Add(parent.Number, big1)
-	return calcDifficulty(time, parent)
+	return calcDifficulty(config.Inihash, time, parent)
 
 }
 
+// TargetBlockTime returns the number of seconds between blocks the difficulty
+// adjustment of the given network aims for.
+func TargetBlockTime(config *params.ChainConfig) (uint64, error) {
+	if config.Inihash == nil || config.Inihash.TargetBlockTime == 0 {
+		return 0, errNoTargetBlockTime
+	}
+	return config.Inihash.TargetBlockTime, nil
+}
+
+// difficultyParams returns the target block time, adjustment window, bound
+// divisor and floor of the difficulty adjustment, filling in the defaults.
+func difficultyParams(config *params.InihashConfig) (target, window, bound, floor *big.Int) {
+	target, window, bound, floor = big.NewInt(defaultTargetBlockTime), big.NewInt(defaultDifficultyWindow), params.DifficultyBoundDivisor, params.MinimumDifficulty
+	if config == nil {
+		return target, window, bound, floor
+	}
+	if config.TargetBlockTime > 0 {
+		target = new(big.Int).SetUint64(config.TargetBlockTime)
+	}
+	if config.DifficultyWindow > 0 {
+		window = new(big.Int).SetUint64(config.DifficultyWindow)
+	}
+	if config.DifficultyBoundDivisor > 0 {
+		bound = new(big.Int).SetUint64(config.DifficultyBoundDivisor)
+	}
+	if config.MinimumDifficulty > 0 {
+		floor = new(big.Int).SetUint64(config.MinimumDifficulty)
+	}
+	return target, window, bound, floor
+}
+
 // Some weird constants to avoid constant memory allocs for them.
 var (
 	expDiffPeriod = big.NewInt(100000)
@@ -332,41 +368,53 @@ var (
 
 // calcDifficultyHomestead is the difficulty adjustment algorithm. It returns
 // the difficulty that a new block should have when created at time given the
-// parent block's time and difficulty. The calculation uses the Homestead rules.
-func calcDifficulty(time uint64, parent *types.Header) *big.Int {
+// parent block's time and difficulty. The calculation uses the Homestead rules,
+// retargeted to the network's block time.
+func calcDifficulty(config *params.InihashConfig, time uint64, parent *types.Header) *big.Int {
 	// https://github.com/ethereum/EIPs/blob/master/EIPS/eip-2.md
-	// algorithm:
+	// algorithm, with the mainnet target of 30, window of 5 and bound of 12288:
 	// diff = (parent_diff +
-	//         (parent_diff / 12288 * max(6 - (block_timestamp - parent_timestamp) // 5, -599))
+	//         (parent_diff / bound * max(target // window - (block_timestamp - parent_timestamp) // window, -599))
 	//        )
+	target, window, bound, floor := difficultyParams(config)
+
 	bigTime := new(big.Int).SetUint64(time)
 	bigParentTime := new(big.Int).SetUint64(parent.Time)
 	// holds intermediate values to make the algo easier to read & audit
 	x := new(big.Int)
 	y := new(big.Int)
-	// 1 - (block_timestamp - parent_timestamp) // 10
+	// target // window - (block_timestamp - parent_timestamp) // window
 	x.Sub(bigTime, bigParentTime)
-	x.Div(x, big5)
-	x.Sub(big6, x)
+	x.Div(x, window)
+	x.Sub(new(big.Int).Div(target, window), x)
 	// max(1 - (block_timestamp - parent_timestamp) // 10, -99)
 	if x.Cmp(bigMinus599) < 0 {
 		x.Set(bigMinus599)
 	}
-	// (parent_diff + parent_diff // 2048 * max(1 - (block_timestamp - parent_timestamp) // 10, -99))
-	y.Div(parent.Difficulty, params.DifficultyBoundDivisor)
+	// (parent_diff + parent_diff // bound * max(target // window - (block_timestamp - parent_timestamp) // window, -599))
+	y.Div(parent.Difficulty, bound)
 	x.Mul(y, x)
 	x.Add(parent.Difficulty, x)
 
 	// minimum difficulty can ever be (before exponential factor)
-	if x.Cmp(params.MinimumDifficulty) < 0 {
-		x.Set(params.MinimumDifficulty)
+	if x.Cmp(floor) < 0 {
+		x.Set(floor)
 	}
 
 	return x
 }
 
 // Exported for fuzzing
-var BaseDifficultyCalulator = calcDifficulty
+var BaseDifficultyCalulator = func(time uint64, parent *types.Header) *big.Int {
+	return calcDifficulty(nil, time, parent)
+}
+
+// VerifyHeaderPoW checks whether the given header carries a valid proof-of-work
+// seal. Unlike VerifyHeader it doesn't look up the parent or any other chain
+// data, so it can be used to validate externally mined shares offline.
+func (inihash *Inihash) VerifyHeaderPoW(header *types.Header) error {
+	return inihash.verifySeal(nil, header, false)
+}
 
 // verifySeal checks whether a block satisfies the PoW difficulty requirements,
 // either using the usual inihash cache for it, or alternatively using a full DAG
@@ -395,13 +443,23 @@ func (inihash *Inihash) verifySeal(chain consensus.ChainHeaderReader, header *ty
 		//digest []byte
 		result []byte
 	)
+	// The seal hash commits to the difficulty, so a remembered result holds
+	sealHash := inihash.SealHash(header)
+	key := sealCacheKey{sealHash: sealHash, nonce: header.Nonce, extraNonce: header.ExtraNonce}
+	if valid, ok := inihash.cachedSeal(key); ok {
+		if !valid {
+			return errInvalidPoW
+		}
+		return nil
+	}
 	// If fast-but-heavy PoW verification was requested, use an inihash dataset
-	headerHash := inihash.SealHash(header).Bytes()
-	result = versaHash.VersaHash(headerHash, header.Nonce[:], header.ExtraNonce[:])
+	result = versaHash.VersaHash(sealHash.Bytes(), header.Nonce[:], header.ExtraNonce[:])
 
 	target := new(big.Int).Div(two256, header.Difficulty)
 
-	if new(big.Int).SetBytes(result).Cmp(target) > 0 {
+	valid := new(big.Int).SetBytes(result).Cmp(target) <= 0
+	inihash.cacheSeal(key, valid)
+	if !valid {
 		return errInvalidPoW
 	}
 	return nil
@@ -418,6 +476,9 @@ func (inihash *Inihash) Prepare(chain consensus.ChainHeaderReader, header *types
 	header.TeamAddress = common.Address{}
 	header.TeamRate = 0
 	header.Difficulty = inihash.CalcDifficulty(chain, header.Time, parent)
+	if extra := inihash.ExtraData(); extra != nil {
+		header.Extra = extra
+	}
 	return nil
 }
 
@@ -492,6 +553,45 @@ var (
 	big32 = big.NewInt(32)
 )
 
+// blockRewardAt returns the static reward of the block with the given number.
+func blockRewardAt(config *params.ChainConfig, number *big.Int) *big.Int {
+	if config.ChainID.Int64() == 7233 {
+		//mainnet
+		return CalBlockReward(number.Uint64(), 50)
+	}
+	return CalBlockReward(number.Uint64(), 1)
+}
+
+// verifyUncleRewards checks that the rewards paid to the uncles of the block stay
+// within the budget set by the chain configuration, expressed in units of the
+// largest reward a single uncle can earn.
+func verifyUncleRewards(config *params.ChainConfig, block *types.Block) error {
+	if config.Inihash == nil || config.Inihash.UncleRewardBudget == 0 || config.IsCatalyst(block.Number()) {
+		return nil
+	}
+	var (
+		number      = block.Number()
+		blockReward = blockRewardAt(config, number)
+		total       = new(big.Int)
+		r           = new(big.Int)
+	)
+	for _, uncle := range block.Uncles() {
+		r.Add(uncle.Number, big8)
+		r.Sub(r, number)
+		r.Mul(r, blockReward)
+		r.Div(r, big8)
+		total.Add(total, r)
+	}
+	// The closest uncles earn 7/8 of the block reward
+	budget := new(big.Int).Mul(blockReward, big.NewInt(7))
+	budget.Div(budget, big8)
+	budget.Mul(budget, new(big.Int).SetUint64(config.Inihash.UncleRewardBudget))
+	if total.Cmp(budget) > 0 {
+		return fmt.Errorf("uncle rewards exceed budget: have %v, max %v", total, budget)
+	}
+	return nil
+}
+
 // AccumulateRewards credits the coinbase of the given block with the mining
 // reward. The total reward consists of the static block reward and rewards for
 // included uncles. The coinbase of each uncle block is also rewarded.
@@ -501,13 +601,7 @@ func accumulateRewards(config *params.ChainConfig, state *state.StateDB, header
 		return
 	}
 	// Select the correct block reward based on chain progression
-	var blockReward *big.Int
-	if config.ChainID.Int64() == 7233 {
-		//mainnet
-		blockReward = CalBlockReward(header.Number.Uint64(), 50)
-	} else {
-		blockReward = CalBlockReward(header.Number.Uint64(), 1)
-	}
+	blockReward := blockRewardAt(config, header.Number)
 
 	// Accumulate the rewards for the miner and any included uncles
 	reward := new(big.Int).Set(blockReward)
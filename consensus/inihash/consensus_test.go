@@ -25,6 +25,8 @@ import (
 	"path/filepath"
 	"testing"
 
+	"PureChain/common"
+	"PureChain/common/hexutil"
 	"PureChain/common/math"
 	"PureChain/core/types"
 	"PureChain/params"
@@ -87,6 +89,145 @@ func TestCalcDifficulty(t *testing.T) {
 	}
 }
 
+// Tests that the difficulty retargeting defaults to the mainnet parameters and
+// follows the parameters of networks that configure their own.
+func TestCalcDifficultyRetarget(t *testing.T) {
+	parent := &types.Header{Number: big.NewInt(1), Time: 1000, Difficulty: big.NewInt(1 << 32)}
+
+	mainnet := &params.ChainConfig{Inihash: &params.InihashConfig{TargetBlockTime: 30, DifficultyWindow: 5, DifficultyBoundDivisor: 12288}}
+	for _, dt := range []uint64{0, 4, 29, 30, 35, 61, 600, 10000} {
+		want := CalcDifficulty(&params.ChainConfig{}, parent.Time+dt, parent)
+		if have := CalcDifficulty(mainnet, parent.Time+dt, parent); have.Cmp(want) != 0 {
+			t.Errorf("dt %d: difficulty mismatch: have %v, want %v", dt, have, want)
+		}
+	}
+	// A network aiming for 2 second blocks adjusts per second of deviation
+	fast := &params.ChainConfig{Inihash: &params.InihashConfig{TargetBlockTime: 2, DifficultyWindow: 1, DifficultyBoundDivisor: 1024}}
+	step := new(big.Int).Div(parent.Difficulty, big.NewInt(1024))
+
+	tests := []struct {
+		dt   uint64
+		want *big.Int
+	}{
+		{2, parent.Difficulty},
+		{0, new(big.Int).Add(parent.Difficulty, new(big.Int).Mul(step, big.NewInt(2)))},
+		{5, new(big.Int).Sub(parent.Difficulty, new(big.Int).Mul(step, big.NewInt(3)))},
+	}
+	for _, tt := range tests {
+		if have := CalcDifficulty(fast, parent.Time+tt.dt, parent); have.Cmp(tt.want) != 0 {
+			t.Errorf("dt %d: difficulty mismatch: have %v, want %v", tt.dt, have, tt.want)
+		}
+	}
+}
+
+// Tests that the difficulty adjustment clamps at the configured floor on a chain
+// of blocks driving the difficulty down, but never goes below it.
+func TestCalcDifficultyFloor(t *testing.T) {
+	floor := big.NewInt(1 << 19)
+	config := &params.ChainConfig{Inihash: &params.InihashConfig{
+		TargetBlockTime:        2,
+		DifficultyWindow:       1,
+		DifficultyBoundDivisor: 1024,
+		MinimumDifficulty:      floor.Uint64(),
+	}}
+	parent := &types.Header{Number: big.NewInt(1), Time: 1000, Difficulty: big.NewInt(1 << 20)}
+
+	// Blocks of a 2 second network arriving every 60 seconds lower the difficulty
+	// by 58/1024 each, reaching the floor after a dozen blocks
+	reached := -1
+	for i := 0; i < 50; i++ {
+		diff := CalcDifficulty(config, parent.Time+60, parent)
+		switch {
+		case diff.Cmp(floor) < 0:
+			t.Fatalf("block %d: difficulty %v below floor %v", i, diff, floor)
+		case diff.Cmp(floor) == 0 && reached < 0:
+			reached = i
+		case diff.Cmp(floor) > 0 && reached >= 0:
+			t.Fatalf("block %d: difficulty %v left the floor reached at block %d", i, diff, reached)
+		}
+		parent = &types.Header{Number: new(big.Int).Add(parent.Number, big1), Time: parent.Time + 60, Difficulty: diff}
+	}
+	if reached < 0 {
+		t.Fatalf("difficulty never reached the floor: have %v, want %v", parent.Difficulty, floor)
+	}
+	// Without a configured floor the protocol minimum applies
+	parent.Difficulty = new(big.Int).Set(params.MinimumDifficulty)
+	if diff := CalcDifficulty(&params.ChainConfig{}, parent.Time+600, parent); diff.Cmp(params.MinimumDifficulty) != 0 {
+		t.Errorf("difficulty mismatch: have %v, want %v", diff, params.MinimumDifficulty)
+	}
+}
+
+// headerReader is a consensus.ChainHeaderReader serving a fixed set of headers,
+// with the last canonical one as the chain head.
+type headerReader struct {
+	config    *params.ChainConfig
+	headers   map[common.Hash]*types.Header
+	canonical []*types.Header
+}
+
+func (r *headerReader) Config() *params.ChainConfig { return r.config }
+func (r *headerReader) CurrentHeader() *types.Header {
+	if len(r.canonical) == 0 {
+		return nil
+	}
+	return r.canonical[len(r.canonical)-1]
+}
+func (r *headerReader) GetHeader(hash common.Hash, number uint64) *types.Header {
+	return r.headers[hash]
+}
+func (r *headerReader) GetHeaderByNumber(number uint64) *types.Header {
+	if number >= uint64(len(r.canonical)) {
+		return nil
+	}
+	return r.canonical[number]
+}
+func (r *headerReader) GetHeaderByHash(hash common.Hash) *types.Header {
+	return r.headers[hash]
+}
+
+// Tests that the extra-data set over the miner API overrides the miner's in the
+// prepared headers, until it is reset.
+func TestPrepareExtraData(t *testing.T) {
+	ethash := NewFaker()
+
+	parent := &types.Header{Number: big.NewInt(1), Time: 1000, Difficulty: big.NewInt(1 << 20)}
+	config := &params.ChainConfig{ForbiddenExtraPrefixes: []hexutil.Bytes{[]byte("pool")}}
+	chain := &headerReader{config: config, headers: map[common.Hash]*types.Header{parent.Hash(): parent}}
+	miner := &PrivateMinerAPI{chain: chain, inihash: ethash}
+
+	prepare := func() []byte {
+		header := &types.Header{ParentHash: parent.Hash(), Number: big.NewInt(2), Time: 1010, Extra: []byte("miner")}
+		if err := ethash.Prepare(chain, header); err != nil {
+			t.Fatalf("failed to prepare header: %v", err)
+		}
+		return header.Extra
+	}
+	if extra := prepare(); string(extra) != "miner" {
+		t.Errorf("extra-data overridden without a value set: %q", extra)
+	}
+	if err := miner.SetExtraData([]byte("vanity")); err != nil {
+		t.Fatalf("failed to set extra-data: %v", err)
+	}
+	if extra := prepare(); string(extra) != "vanity" {
+		t.Errorf("extra-data mismatch: have %q, want %q", extra, "vanity")
+	}
+	if err := miner.SetExtraData(make([]byte, params.MaximumExtraDataSize+1)); err == nil {
+		t.Error("oversized extra-data accepted")
+	}
+	if err := miner.SetExtraData([]byte("pool tag")); err == nil {
+		t.Error("extra-data with a reserved prefix accepted")
+	}
+	if extra := prepare(); string(extra) != "vanity" {
+		t.Errorf("extra-data changed by rejected value: %q", extra)
+	}
+	if err := miner.SetExtraData(nil); err != nil {
+		t.Fatalf("failed to reset extra-data: %v", err)
+	}
+	if extra := prepare(); string(extra) != "miner" {
+		t.Errorf("extra-data not reset: %q", extra)
+	}
+}
+
 func randSlice(min, max uint32) []byte {
 	var b = make([]byte, 4)
 	rand.Read(b)
@@ -28,4 +28,11 @@ const (
 	// difficultyBoundDivisorBitShift is the bound divisor of the difficulty (2048),
 	// This constant is the right-shifts to use for the division.
 	difficultyBoundDivisor = 11
+
+	// defaultTargetBlockTime is the block time the difficulty adjustment aims
+	// for if the network doesn't define one.
+	defaultTargetBlockTime = 30
+	// defaultDifficultyWindow is the block time deviation per difficulty
+	// adjustment step if the network doesn't define one.
+	defaultDifficultyWindow = 5
 )
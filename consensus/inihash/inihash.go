@@ -19,15 +19,20 @@ package inihash
 
 import (
 	"errors"
+	"fmt"
 	"math/big"
 	"math/rand"
 	"sync"
+	"sync/atomic"
 	"time"
 	"unsafe"
 
+	"PureChain/common"
 	"PureChain/consensus"
+	"PureChain/core/types"
 	"PureChain/log"
 	"PureChain/metrics"
+	"PureChain/params"
 	"PureChain/rpc"
 	"github.com/hashicorp/golang-lru/simplelru"
 )
@@ -46,8 +51,17 @@ var (
 
 	// dumpMagic is a dataset dump header to sanity check a data dump.
 	dumpMagic = []uint32{0xbaddcafe, 0xfee1dead}
+
+	// sealCacheHitMeter and sealCacheMissMeter count the seal verifications
+	// answered from and missing the seal cache, giving its hit rate.
+	sealCacheHitMeter  = metrics.NewRegisteredMeter("inihash/sealcache/hit", nil)
+	sealCacheMissMeter = metrics.NewRegisteredMeter("inihash/sealcache/miss", nil)
 )
 
+// defaultSealCacheSize is the number of verified seals remembered if the config
+// doesn't say otherwise.
+const defaultSealCacheSize = 4096
+
 func init() {
 	sharedConfig := Config{
 		PowMode:       ModeNormal,
@@ -127,6 +141,44 @@ const (
 	ModeFullFake
 )
 
+// Mining roles reported by MiningRole.
+const (
+	MiningRoleSolo     = "solo"     // Blocks are sealed by the local CPU miner
+	MiningRolePool     = "pool"     // Work is only handed out to remote miners
+	MiningRoleDisabled = "disabled" // The node isn't sealing at all
+)
+
+// MiningAlgo identifies the proof-of-work algorithm miners have to run on the
+// work packages handed out by the remote sealer.
+type MiningAlgo uint8
+
+const (
+	AlgoVersaHash MiningAlgo = iota // versaHash over the seal hash and nonces
+)
+
+// miningAlgo is the algorithm the blocks are currently sealed with.
+const miningAlgo = AlgoVersaHash
+
+// String returns the canonical name of the algorithm as reported to miners.
+func (algo MiningAlgo) String() string {
+	switch algo {
+	case AlgoVersaHash:
+		return "versahash"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(algo))
+	}
+}
+
+// ParseMiningAlgo parses the canonical name of a mining algorithm.
+func ParseMiningAlgo(name string) (MiningAlgo, error) {
+	switch name {
+	case "versahash":
+		return AlgoVersaHash, nil
+	default:
+		return 0, fmt.Errorf("unknown mining algorithm %q", name)
+	}
+}
+
 // Config are the configuration parameters of the inihash.
 type Config struct {
 	CacheDir         string
@@ -143,6 +195,26 @@ type Config struct {
 	// be block header JSON objects instead of work package arrays.
 	NotifyFull bool
 
+	// Period over which the rate of solutions submitted by remote
+	// miners is averaged (0 = one minute).
+	ShareRateWindow time.Duration
+
+	// TCP address to serve stratum miners on (empty = disabled).
+	StratumAddr string
+
+	// Highest hash rate a single remote miner may report, in hashes
+	// per second (0 = 10 TH/s).
+	MaxHashrate uint64
+
+	// Number of seal verification results remembered to skip recomputing
+	// the PoW of headers seen again, e.g. on reorgs (0 = 4096, negative =
+	// disabled).
+	SealCacheSize int
+
+	// Time a remote miner waits for the sealer to hand out work before
+	// giving up (0 = 5 seconds, negative = no deadline).
+	WorkTimeout time.Duration
+
 	Log log.Logger `toml:"-"`
 }
 
@@ -157,6 +229,13 @@ type Inihash struct {
 	update   chan struct{} // Notification channel to update mining parameters
 	hashrate metrics.Meter // Meter tracking the average hashrate
 	remote   *remoteSealer
+	stratum  *StratumServer
+	sealing  int32  // Number of sealing sessions in progress (atomic)
+	paused   int32  // Whether sealing was paused by the operator (atomic)
+	extra    []byte // Extra-data overriding the miner's in sealed blocks (nil = keep the miner's)
+
+	seals     *simplelru.LRU // Results of fully computed seal verifications (nil = disabled)
+	sealsLock sync.Mutex     // Protects the seal cache
 
 	// The fields below are hooks for testing
 	shared    *Inihash      // Shared PoW verifier to avoid cache regeneration
@@ -193,10 +272,60 @@ func New(config Config, notify []string, noverify bool, chainId *big.Int) *Iniha
 	if config.PowMode == ModeShared {
 		ethash.shared = sharedEthash
 	}
+	if size := config.SealCacheSize; size >= 0 {
+		if size == 0 {
+			size = defaultSealCacheSize
+		}
+		ethash.seals, _ = simplelru.NewLRU(size, nil)
+	}
 	ethash.remote = startRemoteSealer(ethash, notify, noverify)
+	if config.StratumAddr != "" {
+		stratum := NewStratumServer(ethash, config.StratumAddr)
+		if err := stratum.Start(); err != nil {
+			config.Log.Error("Failed to start stratum server", "addr", config.StratumAddr, "err", err)
+		} else {
+			ethash.stratum = stratum
+		}
+	}
 	return ethash
 }
 
+// sealCacheKey identifies a seal by the header it commits to and its nonces.
+type sealCacheKey struct {
+	sealHash   common.Hash
+	nonce      types.BlockNonce
+	extraNonce types.BlockNonce
+}
+
+// cachedSeal returns the remembered verification result of a seal, if any.
+func (inihash *Inihash) cachedSeal(key sealCacheKey) (valid bool, ok bool) {
+	if inihash.seals == nil {
+		return false, false
+	}
+	inihash.sealsLock.Lock()
+	defer inihash.sealsLock.Unlock()
+
+	cached, ok := inihash.seals.Get(key)
+	if !ok {
+		sealCacheMissMeter.Mark(1)
+		return false, false
+	}
+	sealCacheHitMeter.Mark(1)
+	return cached.(bool), true
+}
+
+// cacheSeal remembers the verification result of a seal. It must only be called
+// with results of a full PoW computation, never with shortcut rejections.
+func (inihash *Inihash) cacheSeal(key sealCacheKey, valid bool) {
+	if inihash.seals == nil {
+		return
+	}
+	inihash.sealsLock.Lock()
+	defer inihash.sealsLock.Unlock()
+
+	inihash.seals.Add(key, valid)
+}
+
 // NewTester creates a small sized inihash PoW scheme useful only for testing
 // purposes.
 func NewTester(notify []string, noverify bool) *Inihash {
@@ -265,12 +394,24 @@ func (inihash *Inihash) Close() error {
 		if inihash.remote == nil {
 			return
 		}
+		if inihash.stratum != nil {
+			inihash.stratum.Stop()
+		}
 		close(inihash.remote.requestExit)
 		<-inihash.remote.exitCh
 	})
 	return nil
 }
 
+// Closing returns a channel closed once the engine shut down its remote sealer.
+// Engines without a remote sealer return nil, which never fires.
+func (inihash *Inihash) Closing() <-chan struct{} {
+	if inihash.remote == nil {
+		return nil
+	}
+	return inihash.remote.exitCh
+}
+
 // Threads returns the number of mining threads currently enabled. This doesn't
 // necessarily mean that mining is running!
 func (inihash *Inihash) Threads() int {
@@ -302,6 +443,97 @@ func (inihash *Inihash) SetThreads(threads int) {
 	}
 }
 
+// SetExtraData overrides the extra-data of the blocks prepared for sealing from
+// now on. A nil value reverts to the extra-data set by the miner. Extra-data
+// starting with a prefix the chain reserved is rejected, as the blocks carrying
+// it would be.
+func (inihash *Inihash) SetExtraData(config *params.ChainConfig, extra []byte) error {
+	if uint64(len(extra)) > params.MaximumExtraDataSize {
+		return fmt.Errorf("extra-data too long: %d > %d bytes", len(extra), params.MaximumExtraDataSize)
+	}
+	if config != nil {
+		if prefix, ok := config.ForbiddenExtraPrefix(extra); ok {
+			return fmt.Errorf("extra-data starts with reserved prefix %x", prefix)
+		}
+	}
+	inihash.lock.Lock()
+	defer inihash.lock.Unlock()
+
+	inihash.extra = common.CopyBytes(extra)
+	return nil
+}
+
+// ExtraData returns the extra-data overriding the miner's, or nil if none is set.
+func (inihash *Inihash) ExtraData() []byte {
+	inihash.lock.Lock()
+	defer inihash.lock.Unlock()
+
+	return common.CopyBytes(inihash.extra)
+}
+
+// Pause stops handing out work to remote miners and stops the local CPU miner,
+// without tearing down the sealing sessions. Mining picks up again on Resume.
+func (inihash *Inihash) Pause() {
+	inihash.setPaused(true)
+}
+
+// Resume restarts mining paused by Pause.
+func (inihash *Inihash) Resume() {
+	inihash.setPaused(false)
+}
+
+// Paused reports whether mining is currently paused.
+func (inihash *Inihash) Paused() bool {
+	// If we're running a shared PoW, the state is kept by that instead
+	if inihash.shared != nil {
+		return inihash.shared.Paused()
+	}
+	return atomic.LoadInt32(&inihash.paused) == 1
+}
+
+// setPaused updates the pause flag and pings any running seal to stop or restart
+// its mining threads.
+func (inihash *Inihash) setPaused(paused bool) {
+	// If we're running a shared PoW, pause that instead
+	if inihash.shared != nil {
+		inihash.shared.setPaused(paused)
+		return
+	}
+	var flag int32
+	if paused {
+		flag = 1
+	}
+	if atomic.SwapInt32(&inihash.paused, flag) == flag {
+		return
+	}
+	select {
+	case inihash.update <- struct{}{}:
+	default:
+	}
+}
+
+// MiningRole reports whether the node is currently sealing blocks with its local
+// CPU miner, only handing out work to remote miners, or not sealing at all.
+func (inihash *Inihash) MiningRole() string {
+	// If we're running a shared PoW, the role is decided by that instead
+	if inihash.shared != nil {
+		return inihash.shared.MiningRole()
+	}
+	if atomic.LoadInt32(&inihash.sealing) == 0 {
+		return MiningRoleDisabled
+	}
+	if inihash.remote != nil && inihash.Threads() < 0 {
+		return MiningRolePool
+	}
+	return MiningRoleSolo
+}
+
+// FullFake implements consensus.Faker, reporting whether the engine runs in the
+// full fake mode, accepting all headers without verifying them.
+func (inihash *Inihash) FullFake() bool {
+	return inihash.config.PowMode == ModeFullFake
+}
+
 // Hashrate implements PoW, returning the measured rate of the search invocations
 // per second over the last minute.
 // Note the returned hashrate includes local hashrate, but also includes the total
@@ -342,15 +574,20 @@ func (inihash *Inihash) APIs(chain consensus.ChainHeaderReader) []rpc.API {
 		{
 			Namespace: "eth",
 			Version:   "1.0",
-			Service:   &API{inihash},
+			Service:   &API{chain: chain, inihash: inihash},
 			Public:    true,
 		},
 		{
 			Namespace: "inihash",
 			Version:   "1.0",
-			Service:   &API{inihash},
+			Service:   &API{chain: chain, inihash: inihash},
 			Public:    true,
 		},
+		{
+			Namespace: "miner",
+			Version:   "1.0",
+			Service:   &PrivateMinerAPI{chain: chain, inihash: inihash},
+		},
 	}
 }
 
@@ -17,6 +17,7 @@
 package inihash
 
 import (
+	"errors"
 	"io/ioutil"
 	"math/big"
 	"math/rand"
@@ -28,6 +29,7 @@ import (
 	"PureChain/common"
 	"PureChain/common/hexutil"
 	"PureChain/core/types"
+	"PureChain/params"
 )
 
 // Tests that inihash works correctly in test mode.
@@ -54,6 +56,39 @@ func TestTestMode(t *testing.T) {
 	}
 }
 
+// Tests that the results of full seal verifications are cached, while shortcut
+// rejections are not.
+func TestSealCache(t *testing.T) {
+	ethash := NewTester(nil, false)
+	defer ethash.Close()
+
+	for i, tt := range []struct {
+		difficulty *big.Int
+		err        error
+		cached     bool
+	}{
+		{big.NewInt(1), nil, true},
+		{new(big.Int).Lsh(big1, 255), errInvalidPoW, true},
+		{big.NewInt(0), errInvalidDifficulty, false},
+	} {
+		header := &types.Header{Number: big.NewInt(int64(i + 1)), Difficulty: tt.difficulty}
+		key := sealCacheKey{sealHash: ethash.SealHash(header), nonce: header.Nonce, extraNonce: header.ExtraNonce}
+
+		for j := 0; j < 2; j++ {
+			if err := ethash.verifySeal(nil, header, false); err != tt.err {
+				t.Errorf("test %d, run %d: error mismatch: have %v, want %v", i, j, err, tt.err)
+			}
+		}
+		valid, ok := ethash.cachedSeal(key)
+		if ok != tt.cached {
+			t.Errorf("test %d: cached mismatch: have %v, want %v", i, ok, tt.cached)
+		}
+		if ok && valid != (tt.err == nil) {
+			t.Errorf("test %d: cached result mismatch: have %v, want %v", i, valid, tt.err == nil)
+		}
+	}
+}
+
 // This test checks that cache lru logic doesn't crash under load.
 // It reproduces https://github.com/Project-DeCloud/chain/issues/14943
 func TestCacheFileEvict(t *testing.T) {
@@ -101,7 +136,7 @@ func TestRemoteSealer(t *testing.T) {
 	ethash := NewTester(nil, false)
 	defer ethash.Close()
 
-	api := &API{ethash}
+	api := &API{inihash: ethash}
 	if _, err := api.GetWork(); err != errNoMiningWork {
 		t.Error("expect to return an error indicate there is no mining work")
 	}
@@ -114,12 +149,24 @@ func TestRemoteSealer(t *testing.T) {
 	ethash.Seal(nil, block, results, nil)
 
 	var (
-		work [4]string
+		work [5]string
 		err  error
 	)
 	if work, err = api.GetWork(); err != nil || work[0] != sealhash.Hex() {
 		t.Error("expect to return a mining work has same hash")
 	}
+	if algo, err := ParseMiningAlgo(work[4]); err != nil || algo != miningAlgo {
+		t.Errorf("expect to return the active mining algorithm, got %q", work[4])
+	}
+	if named, err := api.GetWorkJSON(); err != nil || named.HeaderHash != work[0] || named.Target != work[1] || named.Number != work[2] || named.Algo != work[4] {
+		t.Errorf("named work package mismatch: have %+v, want %v", named, work)
+	}
+	if diff, err := api.GetMiningDifficulty(); err != nil || diff.ToInt().Cmp(header.Difficulty) != 0 {
+		t.Errorf("mining difficulty mismatch: have %v, want %v", diff, header.Difficulty)
+	}
+	if target, err := api.GetTarget(); err != nil || target.String() != work[1] {
+		t.Errorf("target mismatch: have %v, want %v", target, work[1])
+	}
 
 	if res := api.SubmitWork(types.BlockNonce{}, types.BlockNonce{}, sealhash); res {
 		t.Error("expect to return false when submit a fake solution")
@@ -135,6 +182,122 @@ func TestRemoteSealer(t *testing.T) {
 	}
 }
 
+// Tests that the most recent work packages are returned newest first, in the
+// same layout as the detailed current work.
+func TestGetRecentWork(t *testing.T) {
+	ethash := NewTester(nil, false)
+	defer ethash.Close()
+
+	api := &API{inihash: ethash}
+	if _, err := api.GetRecentWork(0); err == nil {
+		t.Error("invalid work count accepted")
+	}
+	var hashes []common.Hash
+	for i := int64(1); i <= 3; i++ {
+		header := &types.Header{Number: big.NewInt(i), Difficulty: big.NewInt(100)}
+		ethash.Seal(nil, types.NewBlockWithHeader(header), make(chan *types.Block), nil)
+		hashes = append(hashes, ethash.SealHash(header))
+	}
+	current, err := api.GetWorkDetailed()
+	if err != nil {
+		t.Fatalf("failed to get current work: %v", err)
+	}
+	works, err := api.GetRecentWork(2)
+	if err != nil {
+		t.Fatalf("failed to get recent work: %v", err)
+	}
+	if len(works) != 2 {
+		t.Fatalf("work count mismatch: have %d, want 2", len(works))
+	}
+	if works[0] != *current {
+		t.Errorf("newest work mismatch: have %+v, want %+v", works[0], *current)
+	}
+	for i, work := range works {
+		if want := hashes[len(hashes)-1-i]; work.Hash != want || uint64(work.Number) != uint64(len(hashes)-i) {
+			t.Errorf("work %d: mismatch: have %x #%d, want %x #%d", i, work.Hash, work.Number, want, len(hashes)-i)
+		}
+	}
+}
+
+// Tests that pausing mining withholds work from remote miners until resumed, and
+// that the controls are only served on the private miner namespace.
+func TestPauseMining(t *testing.T) {
+	ethash := NewTester(nil, false)
+	defer ethash.Close()
+
+	api, miner := &API{inihash: ethash}, &PrivateMinerAPI{inihash: ethash}
+	header := &types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(100)}
+	ethash.Seal(nil, types.NewBlockWithHeader(header), make(chan *types.Block), nil)
+
+	miner.PauseMining()
+	if !ethash.Paused() {
+		t.Fatal("mining not paused")
+	}
+	if _, err := api.GetWork(); err != errMiningPaused {
+		t.Errorf("paused work error mismatch: have %v, want %v", err, errMiningPaused)
+	}
+	if _, err := api.GetWorkDetailed(); err != errMiningPaused {
+		t.Errorf("paused detailed work error mismatch: have %v, want %v", err, errMiningPaused)
+	}
+	miner.ResumeMining()
+	if ethash.Paused() {
+		t.Fatal("mining still paused")
+	}
+	if work, err := api.GetWork(); err != nil || work[0] != ethash.SealHash(header).Hex() {
+		t.Errorf("resumed work mismatch: have %v %v, want %s", work, err, ethash.SealHash(header).Hex())
+	}
+	for _, api := range ethash.APIs(nil) {
+		if _, ok := api.Service.(*PrivateMinerAPI); ok && (api.Public || api.Namespace != "miner") {
+			t.Errorf("mining controls exposed on %s namespace, public %t", api.Namespace, api.Public)
+		}
+	}
+}
+
+// Tests that work packages can be requested for arbitrary epochs in test mode only.
+func TestGetWorkForNumber(t *testing.T) {
+	ethash := NewTester(nil, false)
+	defer ethash.Close()
+
+	api := &API{inihash: ethash}
+	header := &types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(100)}
+	ethash.Seal(nil, types.NewBlockWithHeader(header), make(chan *types.Block), nil)
+
+	for _, number := range []uint64{epochLength - 1, epochLength} {
+		work, err := api.GetWorkForNumber(number)
+		if err != nil {
+			t.Fatalf("block %d: failed to get work: %v", number, err)
+		}
+		if work.Hash != ethash.SealHash(header) {
+			t.Errorf("block %d: hash mismatch: have %x, want %x", number, work.Hash, ethash.SealHash(header))
+		}
+		if want := common.BytesToHash(SeedHash(number)); work.SeedHash != want {
+			t.Errorf("block %d: seed hash mismatch: have %x, want %x", number, work.SeedHash, want)
+		}
+		if uint64(work.Number) != number {
+			t.Errorf("block %d: number mismatch: have %d, want %d", number, work.Number, number)
+		}
+	}
+	normal := &API{inihash: &Inihash{config: Config{PowMode: ModeNormal}}}
+	if _, err := normal.GetWorkForNumber(1); err == nil {
+		t.Error("work for arbitrary number handed out in normal mode")
+	}
+}
+
+func TestMiningAlgoRoundTrip(t *testing.T) {
+	for _, algo := range []MiningAlgo{AlgoVersaHash} {
+		parsed, err := ParseMiningAlgo(algo.String())
+		if err != nil {
+			t.Fatalf("failed to parse %v: %v", algo, err)
+		}
+		if parsed != algo {
+			t.Errorf("round trip mismatch: have %v, want %v", parsed, algo)
+		}
+	}
+	if _, err := ParseMiningAlgo("ethash"); err == nil {
+		t.Error("expect to reject unknown algorithm")
+	}
+}
+
 func TestHashrate(t *testing.T) {
 	var (
 		hashrate = []hexutil.Uint64{100, 200, 300}
@@ -148,7 +311,7 @@ func TestHashrate(t *testing.T) {
 		t.Error("expect the result should be zero")
 	}
 
-	api := &API{ethash}
+	api := &API{inihash: ethash}
 	for i := 0; i < len(hashrate); i += 1 {
 		if res := api.SubmitHashrate(hashrate[i], ids[i]); !res {
 			t.Error("remote miner submit hashrate failed")
@@ -160,17 +323,226 @@ func TestHashrate(t *testing.T) {
 	}
 }
 
+func TestHashrateCeiling(t *testing.T) {
+	ethash := New(Config{PowMode: ModeTest, MaxHashrate: 1000}, nil, false, nil)
+	defer ethash.Close()
+
+	api := &API{inihash: ethash}
+	if res := api.SubmitHashrate(hexutil.Uint64(1001), common.HexToHash("a")); res {
+		t.Error("expect to reject hash rate above the ceiling")
+	}
+	if res := api.SubmitHashrate(hexutil.Uint64(1000), common.HexToHash("b")); !res {
+		t.Error("expect to accept hash rate at the ceiling")
+	}
+	if tot := ethash.Hashrate(); tot != 1000 {
+		t.Errorf("total hash rate mismatch: have %v, want 1000", tot)
+	}
+}
+
 func TestClosedRemoteSealer(t *testing.T) {
 	ethash := NewTester(nil, false)
 	time.Sleep(1 * time.Second) // ensure exit channel is listening
 	ethash.Close()
 
-	api := &API{ethash}
+	api := &API{inihash: ethash}
 	if _, err := api.GetWork(); err != errEthashStopped {
 		t.Error("expect to return an error to indicate inihash is stopped")
 	}
 
+	if _, err := api.GetWorkDetailed(); !errors.Is(err, ErrSealerClosing) {
+		t.Errorf("error mismatch: have %v, want %v", err, ErrSealerClosing)
+	}
 	if res := api.SubmitHashrate(hexutil.Uint64(100), common.HexToHash("a")); res {
 		t.Error("expect to return false when submit hashrate to a stopped inihash")
 	}
+	select {
+	case <-ethash.Closing():
+	default:
+		t.Error("closing channel not closed")
+	}
+	// Sealing must not block on the stopped remote sealer
+	header := &types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(100)}
+	if err := ethash.Seal(nil, types.NewBlockWithHeader(header), make(chan *types.Block), nil); !errors.Is(err, ErrSealerClosing) {
+		t.Errorf("error mismatch: have %v, want %v", err, ErrSealerClosing)
+	}
+}
+
+func TestVerifyWorkBatch(t *testing.T) {
+	ethash := NewFakeFailer(2)
+	defer ethash.Close()
+
+	api := &API{inihash: ethash}
+	items := []WorkVerification{
+		{Header: &types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(100)}},
+		{Header: &types.Header{Number: big.NewInt(2), Difficulty: big.NewInt(100)}},
+		{Header: &types.Header{Number: big.NewInt(3), Difficulty: big.NewInt(100)}},
+		{},
+	}
+	results, err := api.VerifyWorkBatch(items)
+	if err != nil {
+		t.Fatalf("failed to verify batch: %v", err)
+	}
+	if len(results) != len(items) {
+		t.Fatalf("result count mismatch: have %d, want %d", len(results), len(items))
+	}
+	for i, want := range []bool{true, false, true, false} {
+		if results[i] != want {
+			t.Errorf("item %d: verification mismatch: have %v, want %v", i, results[i], want)
+		}
+	}
+	if _, err := api.VerifyWorkBatch(make([]WorkVerification, maxWorkBatch)); err != nil {
+		t.Errorf("full batch rejected: %v", err)
+	}
+	if results, err := api.VerifyWorkBatch(make([]WorkVerification, maxWorkBatch+1)); err == nil || results != nil {
+		t.Errorf("oversized batch accepted: %d results, err %v", len(results), err)
+	}
+}
+
+// Tests that the mining role follows the sealing sessions and the thread count,
+// falling back to disabled once sealing stops.
+func TestGetMiningRole(t *testing.T) {
+	ethash := NewTester(nil, false)
+	defer ethash.Close()
+
+	api := &API{inihash: ethash}
+	if role := api.GetMiningRole(); role != MiningRoleDisabled {
+		t.Fatalf("idle role mismatch: have %s, want %s", role, MiningRoleDisabled)
+	}
+	header := &types.Header{Number: big.NewInt(1), Difficulty: new(big.Int).Lsh(big1, 255)}
+	for _, tt := range []struct {
+		threads int
+		role    string
+	}{
+		{-1, MiningRolePool},
+		{1, MiningRoleSolo},
+	} {
+		ethash.SetThreads(tt.threads)
+		stop := make(chan struct{})
+		if err := ethash.Seal(nil, types.NewBlockWithHeader(header), make(chan *types.Block), stop); err != nil {
+			t.Fatalf("threads %d: failed to seal: %v", tt.threads, err)
+		}
+		if role := api.GetMiningRole(); role != tt.role {
+			t.Errorf("threads %d: role mismatch: have %s, want %s", tt.threads, role, tt.role)
+		}
+		close(stop)
+
+		// The sealing session winds down asynchronously
+		for deadline := time.Now().Add(time.Second); api.GetMiningRole() != MiningRoleDisabled && time.Now().Before(deadline); {
+			time.Sleep(10 * time.Millisecond)
+		}
+		if role := api.GetMiningRole(); role != MiningRoleDisabled {
+			t.Errorf("threads %d: stopped role mismatch: have %s, want %s", tt.threads, role, MiningRoleDisabled)
+		}
+	}
+}
+
+// Tests that the epoch info describes the block following the chain head, even
+// while mining is paused.
+func TestGetCurrentEpochInfo(t *testing.T) {
+	ethash := NewTester(nil, false)
+	defer ethash.Close()
+
+	if _, err := (&API{inihash: ethash}).GetCurrentEpochInfo(); err == nil {
+		t.Error("epoch info returned without a chain")
+	}
+	head := &types.Header{Number: big.NewInt(epochLength - 1)}
+	api := &API{chain: &headerReader{config: &params.ChainConfig{}, canonical: []*types.Header{head}}, inihash: ethash}
+
+	// The epoch of the block following the head is described, with or without work
+	check := func() {
+		t.Helper()
+		info, err := api.GetCurrentEpochInfo()
+		if err != nil {
+			t.Fatalf("failed to get epoch info: %v", err)
+		}
+		if info.Epoch != 1 || info.StartBlock != epochLength || info.DAGCached != nil {
+			t.Errorf("epoch info mismatch: have %+v", *info)
+		}
+	}
+	check()
+
+	ethash.Pause()
+	check()
+}
+
+// Tests that the difficulty history returns the canonical difficulties of the
+// requested range, rejecting ranges that are reversed, too large or beyond the head.
+func TestGetDifficultyHistory(t *testing.T) {
+	ethash := NewTester(nil, false)
+	defer ethash.Close()
+
+	if _, err := (&API{inihash: ethash}).GetDifficultyHistory(0, 1); err == nil {
+		t.Error("difficulty history returned without a chain")
+	}
+	var canonical []*types.Header
+	for i := int64(0); i < 5; i++ {
+		canonical = append(canonical, &types.Header{Number: big.NewInt(i), Difficulty: big.NewInt(1000 + i)})
+	}
+	api := &API{chain: &headerReader{config: &params.ChainConfig{}, canonical: canonical}, inihash: ethash}
+
+	for _, tt := range []struct {
+		from, to uint64
+		fail     bool
+	}{
+		{0, 0, false},
+		{1, 4, false},
+		{0, 4, false},
+		{3, 2, true},
+		{4, 5, true},
+		{0, maxDifficultyHistory, true},
+	} {
+		history, err := api.GetDifficultyHistory(tt.from, tt.to)
+		if (err != nil) != tt.fail {
+			t.Errorf("range %d-%d: error mismatch: have %v, want failure %t", tt.from, tt.to, err, tt.fail)
+			continue
+		}
+		if tt.fail {
+			continue
+		}
+		if len(history) != int(tt.to-tt.from+1) {
+			t.Fatalf("range %d-%d: length mismatch: have %d, want %d", tt.from, tt.to, len(history), tt.to-tt.from+1)
+		}
+		for i, diff := range history {
+			if want := canonical[tt.from+uint64(i)].Difficulty; diff.Cmp(want) != 0 {
+				t.Errorf("range %d-%d: block %d difficulty mismatch: have %v, want %v", tt.from, tt.to, tt.from+uint64(i), diff, want)
+			}
+		}
+	}
+}
+
+// Tests that the detailed work package describes the sealed block, and that it's
+// only flagged clean when the work moves to a new parent.
+func TestGetWorkDetailed(t *testing.T) {
+	ethash := NewTester(nil, false)
+	defer ethash.Close()
+
+	api := &API{inihash: ethash}
+	if _, err := api.GetWorkDetailed(); err != errNoMiningWork {
+		t.Errorf("error mismatch without work: have %v, want %v", err, errNoMiningWork)
+	}
+	for i, tt := range []struct {
+		parent common.Hash
+		clean  bool
+	}{
+		{common.Hash{0x01}, true},
+		{common.Hash{0x01}, false},
+		{common.Hash{0x02}, true},
+	} {
+		header := &types.Header{ParentHash: tt.parent, Number: big.NewInt(1), Time: uint64(1000 + i), Difficulty: big.NewInt(100)}
+		ethash.Seal(nil, types.NewBlockWithHeader(header), make(chan *types.Block), nil)
+
+		work, err := api.GetWorkDetailed()
+		if err != nil {
+			t.Fatalf("test %d: failed to get work: %v", i, err)
+		}
+		if work.Timestamp != hexutil.Uint64(header.Time) {
+			t.Errorf("test %d: timestamp mismatch: have %d, want %d", i, work.Timestamp, header.Time)
+		}
+		if work.Clean != tt.clean {
+			t.Errorf("test %d: clean flag mismatch: have %t, want %t", i, work.Clean, tt.clean)
+		}
+	}
+	if _, err := (&API{inihash: &Inihash{}}).GetWorkDetailed(); err == nil {
+		t.Error("work handed out without a remote sealer")
+	}
 }
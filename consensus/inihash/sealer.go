@@ -30,7 +30,9 @@ import (
 	"math/rand"
 	"net/http"
 	"runtime"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"PureChain/common"
@@ -38,16 +40,47 @@ import (
 	"PureChain/common/hexutil"
 	"PureChain/consensus"
 	"PureChain/core/types"
+	"PureChain/event"
 )
 
 const (
 	// staleThreshold is the maximum depth of the acceptable stale but valid inihash solution.
 	staleThreshold = 7
+
+	// submittedRetention is the number of blocks remotely submitted solutions are
+	// remembered for, waiting to be accepted into the canonical chain.
+	submittedRetention = 64
+
+	// hashrateStaleness is the time after which a hash rate submitted by a remote
+	// miner is dropped, unless refreshed. Miners usually resubmit every 5 seconds.
+	hashrateStaleness = 10 * time.Second
+
+	// maxRecentWork is the number of most recent work packages retained for the
+	// proxies matching late submissions to their jobs.
+	maxRecentWork = 64
+
+	// defaultMaxHashrate is the highest hash rate a single remote miner may report
+	// if the configuration doesn't specify a ceiling.
+	defaultMaxHashrate = 10_000_000_000_000
+
+	// defaultShareRateWindow is the period submitted solutions are averaged over
+	// if the configuration doesn't specify one.
+	defaultShareRateWindow = time.Minute
+
+	// defaultWorkTimeout is the time a remote miner waits for work to be handed
+	// out if the configuration doesn't specify a deadline.
+	defaultWorkTimeout = 5 * time.Second
+
+	// hashrateHistorySize is the number of aggregate hash rate samples retained,
+	// covering an hour when sampled on every 5 second cleanup tick.
+	hashrateHistorySize = 720
 )
 
 var (
 	errNoMiningWork      = errors.New("no mining work available yet")
+	errMiningPaused      = errors.New("mining paused")
 	errInvalidSealResult = errors.New("invalid or stale proof-of-work solution")
+	errWorkTimeout       = errors.New("timed out waiting for mining work")
 )
 
 // Seal implements consensus.Engine, attempting to find a nonce that satisfies
@@ -71,6 +104,7 @@ func (inihash *Inihash) Seal(chain consensus.ChainHeaderReader, block *types.Blo
 	}
 	// Create a runner and the multiple search threads it directs
 	abort := make(chan struct{})
+	atomic.AddInt32(&inihash.sealing, 1)
 
 	inihash.lock.Lock()
 	threads := inihash.threads
@@ -78,6 +112,7 @@ func (inihash *Inihash) Seal(chain consensus.ChainHeaderReader, block *types.Blo
 		seed, err := crand.Int(crand.Reader, big.NewInt(math.MaxInt64))
 		if err != nil {
 			inihash.lock.Unlock()
+			atomic.AddInt32(&inihash.sealing, -1)
 			return err
 		}
 		inihash.rand = rand.New(rand.NewSource(seed.Int64()))
@@ -86,12 +121,17 @@ func (inihash *Inihash) Seal(chain consensus.ChainHeaderReader, block *types.Blo
 	if threads == 0 {
 		threads = runtime.NumCPU()
 	}
-	if threads < 0 {
+	if threads < 0 || inihash.Paused() {
 		threads = 0 // Allows disabling local mining without extra logic around local/remote
 	}
-	// Push new work to remote sealer
+	// Push new work to remote sealer, unless it's shutting down
 	if inihash.remote != nil {
-		inihash.remote.workCh <- &sealTask{block: block, results: results}
+		select {
+		case inihash.remote.workCh <- &sealTask{block: block, results: results}:
+		case <-inihash.remote.exitCh:
+			atomic.AddInt32(&inihash.sealing, -1)
+			return errEthashStopped
+		}
 	}
 	var (
 		pend   sync.WaitGroup
@@ -106,11 +146,16 @@ func (inihash *Inihash) Seal(chain consensus.ChainHeaderReader, block *types.Blo
 	}
 	// Wait until sealing is terminated or a nonce is found
 	gopool.Submit(func() {
+		defer atomic.AddInt32(&inihash.sealing, -1)
+
 		var result *types.Block
 		select {
 		case <-stop:
 			// Outside abort, stop all miner threads
 			close(abort)
+		case <-inihash.Closing():
+			// Engine shutting down, stop all miner threads
+			close(abort)
 		case result = <-locals:
 			// One of the threads found a block, abort all others
 			select {
@@ -132,6 +177,31 @@ func (inihash *Inihash) Seal(chain consensus.ChainHeaderReader, block *types.Blo
 	return nil
 }
 
+// SubmitWorkAsync submits a proof-of-work solution like the SubmitWork API, but
+// returns as soon as the sealer picked up the submission instead of waiting for
+// its verification. The outcome is sent on the returned channel: nil if the
+// solution was accepted, an error otherwise. The channel is buffered, so callers
+// not reading it don't leak the sealer goroutine. It's meant for proxies running
+// in-process and isn't served over RPC.
+func (inihash *Inihash) SubmitWorkAsync(nonce types.BlockNonce, extraNonce types.BlockNonce, hash common.Hash) <-chan error {
+	var errc = make(chan error, 1)
+	if inihash.remote == nil {
+		errc <- errors.New("not supported")
+		return errc
+	}
+	select {
+	case inihash.remote.submitWorkCh <- &mineResult{
+		nonce:      nonce,
+		extraNonce: extraNonce,
+		hash:       hash,
+		errc:       errc,
+	}:
+	case <-inihash.remote.exitCh:
+		errc <- errEthashStopped
+	}
+	return errc
+}
+
 // mine is the actual proof-of-work miner that searches for a nonce starting from
 // seed that results in correct final block difficulty.
 func (inihash *Inihash) mine(block *types.Block, id int, seed uint64, abort chan struct{}, found chan *types.Block) {
@@ -204,22 +274,46 @@ const remoteSealerTimeout = 1 * time.Second
 
 type remoteSealer struct {
 	works        map[common.Hash]*types.Block
+	workTimes    map[common.Hash]time.Time
 	rates        map[common.Hash]hashrate
+	shares       []time.Time // Arrival times of the solutions submitted within the share rate window
 	currentBlock *types.Block
 	currentWork  [4]string
+	currentClean bool                       // Whether the current work starts a new job rather than refreshing the previous one
+	recentWork   [maxRecentWork]WorkDetails // Ring buffer of the most recent work packages
+	recentHead   int                        // Index of the next slot to fill in the ring buffer
+	recentCount  int                        // Number of work packages in the ring buffer
+	stats        SubmitStats                // Outcomes of the solutions submitted by remote miners
 	notifyCtx    context.Context
 	cancelNotify context.CancelFunc // cancels all notification requests
 	reqWG        sync.WaitGroup     // tracks notification request goroutines
 
+	rateHistory [hashrateHistorySize]HashratePoint // Ring buffer of the aggregate hash rate samples
+	rateHead    int                                // Index of the next slot to fill in the hash rate ring
+	rateCount   int                                // Number of samples in the hash rate ring
+
+	solved     map[common.Hash]struct{} // Solutions sealed on the current parent, to detect duplicates
+	submitted  map[common.Hash]uint64   // Blocks sealed from remote submissions, by hash
+	submitLock sync.Mutex               // Protects submitted, which is read from outside the loop
+
 	inihash      *Inihash
 	noverify     bool
 	notifyURLs   []string
 	results      chan<- *types.Block
-	workCh       chan *sealTask   // Notification channel to push new work and relative result channel to remote sealer
-	fetchWorkCh  chan *sealWork   // Channel used for remote sealer to fetch mining work
-	submitWorkCh chan *mineResult // Channel used for remote sealer to submit their mining result
-	fetchRateCh  chan chan uint64 // Channel used to gather submitted hash rate for local or remote sealer.
-	submitRateCh chan *hashrate   // Channel used for remote sealer to submit their mining hashrate
+	workCh       chan *sealTask                   // Notification channel to push new work and relative result channel to remote sealer
+	fetchWorkCh  chan *sealWork                   // Channel used for remote sealer to fetch mining work
+	submitWorkCh chan *mineResult                 // Channel used for remote sealer to submit their mining result
+	fetchRateCh  chan chan uint64                 // Channel used to gather submitted hash rate for local or remote sealer.
+	fetchRatesCh chan chan map[common.Hash]uint64 // Channel used to gather the hash rates submitted by each remote miner
+	fetchPendCh  chan chan []PendingWork          // Channel used to list the pending work packages
+	fetchShareCh chan chan float64                // Channel used to gather the rate of submitted solutions
+	fetchBlockCh chan chan *types.Block           // Channel used to retrieve the block of the current work
+	fetchDetlCh  chan chan *WorkDetails           // Channel used to retrieve the details of the current work
+	fetchRecCh   chan chan []WorkDetails          // Channel used to retrieve the most recent work packages
+	fetchStatCh  chan chan SubmitStats            // Channel used to retrieve the outcomes of submitted solutions
+	fetchHistCh  chan chan []HashratePoint        // Channel used to retrieve the aggregate hash rate history
+	submitRateCh chan *hashrate                   // Channel used for remote sealer to submit their mining hashrate
+	workFeed     event.Feed                       // Feed announcing every new work package
 	requestExit  chan struct{}
 	exitCh       chan struct{}
 }
@@ -235,6 +329,7 @@ type mineResult struct {
 	nonce      types.BlockNonce
 	extraNonce types.BlockNonce
 	hash       common.Hash
+	coinbase   *common.Address // Coinbase the miner claims to have mined for, if declared
 
 	errc chan error
 }
@@ -248,6 +343,38 @@ type hashrate struct {
 	done chan struct{}
 }
 
+// PendingWork describes a work package the remote sealer accepts submissions for.
+type PendingWork struct {
+	Hash   common.Hash    `json:"hash"`   // Seal hash of the work package
+	Number hexutil.Uint64 `json:"number"` // Number of the block being sealed
+	Age    hexutil.Uint64 `json:"age"`    // Seconds since the package was created
+}
+
+// WorkDetails is the full description of a work package handed to remote miners.
+type WorkDetails struct {
+	Hash      common.Hash    `json:"hash"`      // Seal hash of the block header
+	SeedHash  common.Hash    `json:"seedHash"`  // Seed hash of the block's epoch
+	Target    common.Hash    `json:"target"`    // Boundary condition, 2^256/difficulty
+	Number    hexutil.Uint64 `json:"number"`    // Number of the block being sealed
+	Algo      string         `json:"algo"`      // Proof-of-work algorithm to mine with
+	Timestamp hexutil.Uint64 `json:"timestamp"` // Timestamp of the block being sealed
+	Clean     bool           `json:"clean"`     // Whether in-flight solutions for previous work must be discarded
+}
+
+// SubmitStats counts the outcomes of the solutions submitted by remote miners.
+type SubmitStats struct {
+	Accepted  uint64 `json:"accepted"`  // Valid solutions passed on to the miner
+	Stale     uint64 `json:"stale"`     // Solutions for work that's unknown or too old
+	Duplicate uint64 `json:"duplicate"` // Solutions for blocks already sealed from a submission
+	Invalid   uint64 `json:"invalid"`   // Solutions failing verification or mined for a foreign coinbase
+}
+
+// HashratePoint is a sample of the aggregate hash rate of the local and remote miners.
+type HashratePoint struct {
+	Timestamp int64  `json:"timestamp"` // Unix time the sample was taken at
+	Rate      uint64 `json:"rate"`      // Combined hash rate at the time of sampling
+}
+
 // sealWork wraps a seal work package for remote sealer.
 type sealWork struct {
 	errc chan error
@@ -263,11 +390,22 @@ func startRemoteSealer(inihash *Inihash, urls []string, noverify bool) *remoteSe
 		notifyCtx:    ctx,
 		cancelNotify: cancel,
 		works:        make(map[common.Hash]*types.Block),
+		workTimes:    make(map[common.Hash]time.Time),
+		solved:       make(map[common.Hash]struct{}),
+		submitted:    make(map[common.Hash]uint64),
 		rates:        make(map[common.Hash]hashrate),
 		workCh:       make(chan *sealTask),
 		fetchWorkCh:  make(chan *sealWork),
 		submitWorkCh: make(chan *mineResult),
 		fetchRateCh:  make(chan chan uint64),
+		fetchRatesCh: make(chan chan map[common.Hash]uint64),
+		fetchPendCh:  make(chan chan []PendingWork),
+		fetchShareCh: make(chan chan float64),
+		fetchBlockCh: make(chan chan *types.Block),
+		fetchDetlCh:  make(chan chan *WorkDetails),
+		fetchRecCh:   make(chan chan []WorkDetails),
+		fetchStatCh:  make(chan chan SubmitStats),
+		fetchHistCh:  make(chan chan []HashratePoint),
 		submitRateCh: make(chan *hashrate),
 		requestExit:  make(chan struct{}),
 		exitCh:       make(chan struct{}),
@@ -295,10 +433,13 @@ func (s *remoteSealer) loop() {
 			s.results = work.results
 			s.makeWork(work.block)
 			s.notifyWork()
+			s.workFeed.Send(s.workDetails())
 
 		case work := <-s.fetchWorkCh:
 			// Return current mining work to remote miner.
-			if s.currentBlock == nil {
+			if s.inihash.Paused() {
+				work.errc <- errMiningPaused
+			} else if s.currentBlock == nil {
 				work.errc <- errNoMiningWork
 			} else {
 				work.res <- s.currentWork
@@ -306,7 +447,7 @@ func (s *remoteSealer) loop() {
 
 		case result := <-s.submitWorkCh:
 			// Verify submitted PoW solution based on maintained mining blocks.
-			if s.submitWork(result.nonce, result.extraNonce, result.hash) {
+			if s.submitWork(result.nonce, result.extraNonce, result.hash, result.coinbase) {
 				result.errc <- nil
 			} else {
 				result.errc <- errInvalidSealResult
@@ -319,25 +460,64 @@ func (s *remoteSealer) loop() {
 
 		case req := <-s.fetchRateCh:
 			// Gather all hash rate submitted by remote sealer.
-			var total uint64
-			for _, rate := range s.rates {
-				// this could overflow
-				total += rate.rate
+			req <- s.remoteRate()
+
+		case req := <-s.fetchRatesCh:
+			// Gather the hash rates submitted by each remote miner.
+			rates := make(map[common.Hash]uint64, len(s.rates))
+			for id, rate := range s.rates {
+				if time.Since(rate.ping) <= hashrateStaleness {
+					rates[id] = rate.rate
+				}
 			}
-			req <- total
+			req <- rates
+
+		case req := <-s.fetchPendCh:
+			// Snapshot all the work packages still accepting submissions.
+			req <- s.pendingWork()
+
+		case req := <-s.fetchBlockCh:
+			// Return the block being sealed by the current work.
+			req <- s.currentBlock
+
+		case req := <-s.fetchDetlCh:
+			// Describe the current mining work in full.
+			req <- s.workDetails()
+
+		case req := <-s.fetchStatCh:
+			// Return the outcomes of the submitted solutions.
+			req <- s.stats
+
+		case req := <-s.fetchHistCh:
+			// Return the aggregate hash rate samples, oldest first.
+			req <- s.hashrateHistory()
+
+		case req := <-s.fetchRecCh:
+			// Return the most recent work packages, newest first.
+			req <- s.recentWorks()
+
+		case req := <-s.fetchShareCh:
+			// Average the submitted solutions over the window.
+			s.pruneShares()
+			req <- float64(len(s.shares)) / s.shareRateWindow().Seconds()
 
 		case <-ticker.C:
 			// Clear stale submitted hash rate.
 			for id, rate := range s.rates {
-				if time.Since(rate.ping) > 10*time.Second {
+				if time.Since(rate.ping) > hashrateStaleness {
 					delete(s.rates, id)
 				}
 			}
+			// Clear submissions which fell out of the share rate window
+			s.pruneShares()
+			// Sample the aggregate hash rate of the local and remote miners
+			s.trackHashrate(time.Now(), uint64(s.inihash.hashrate.Rate1())+s.remoteRate())
 			// Clear stale pending blocks
 			if s.currentBlock != nil {
 				for hash, block := range s.works {
 					if block.NumberU64()+staleThreshold <= s.currentBlock.NumberU64() {
 						delete(s.works, hash)
+						delete(s.workTimes, hash)
 					}
 				}
 			}
@@ -348,6 +528,96 @@ func (s *remoteSealer) loop() {
 	}
 }
 
+// workDetails describes the current work package, or returns nil if there's none.
+func (s *remoteSealer) workDetails() *WorkDetails {
+	if s.currentBlock == nil {
+		return nil
+	}
+	return &WorkDetails{
+		Hash:      common.HexToHash(s.currentWork[0]),
+		SeedHash:  common.BytesToHash(SeedHash(s.currentBlock.NumberU64())),
+		Target:    common.HexToHash(s.currentWork[1]),
+		Number:    hexutil.Uint64(s.currentBlock.NumberU64()),
+		Algo:      miningAlgo.String(),
+		Timestamp: hexutil.Uint64(s.currentBlock.Time()),
+		Clean:     s.currentClean,
+	}
+}
+
+// subscribeWork registers a subscription announcing every new work package.
+func (s *remoteSealer) subscribeWork(ch chan<- *WorkDetails) event.Subscription {
+	return s.workFeed.Subscribe(ch)
+}
+
+// maxHashrate returns the highest hash rate accepted from a single remote miner.
+func (s *remoteSealer) maxHashrate() uint64 {
+	if s.inihash.config.MaxHashrate > 0 {
+		return s.inihash.config.MaxHashrate
+	}
+	return defaultMaxHashrate
+}
+
+// remoteRate sums the hash rates recently reported by the remote miners.
+func (s *remoteSealer) remoteRate() uint64 {
+	var total uint64
+	for _, rate := range s.rates {
+		// Rigs that stopped reporting don't count until the next cleanup
+		if time.Since(rate.ping) > hashrateStaleness {
+			continue
+		}
+		// this could overflow
+		total += rate.rate
+	}
+	return total
+}
+
+// trackHashrate records an aggregate hash rate sample in the ring buffer,
+// overwriting the oldest one if it's full.
+func (s *remoteSealer) trackHashrate(now time.Time, rate uint64) {
+	s.rateHistory[s.rateHead] = HashratePoint{Timestamp: now.Unix(), Rate: rate}
+	s.rateHead = (s.rateHead + 1) % hashrateHistorySize
+	if s.rateCount < hashrateHistorySize {
+		s.rateCount++
+	}
+}
+
+// hashrateHistory returns the retained hash rate samples, oldest first.
+func (s *remoteSealer) hashrateHistory() []HashratePoint {
+	history := make([]HashratePoint, 0, s.rateCount)
+	for i := s.rateCount; i > 0; i-- {
+		history = append(history, s.rateHistory[(s.rateHead-i+hashrateHistorySize)%hashrateHistorySize])
+	}
+	return history
+}
+
+// workTimeout returns the time a remote miner waits for work to be handed out,
+// or zero if it may wait indefinitely.
+func (s *remoteSealer) workTimeout() time.Duration {
+	switch timeout := s.inihash.config.WorkTimeout; {
+	case timeout < 0:
+		return 0
+	case timeout > 0:
+		return timeout
+	}
+	return defaultWorkTimeout
+}
+
+// shareRateWindow returns the period submitted solutions are averaged over.
+func (s *remoteSealer) shareRateWindow() time.Duration {
+	if window := s.inihash.config.ShareRateWindow; window > 0 {
+		return window
+	}
+	return defaultShareRateWindow
+}
+
+// pruneShares drops the submissions older than the share rate window.
+func (s *remoteSealer) pruneShares() {
+	cutoff := time.Now().Add(-s.shareRateWindow())
+
+	i := sort.Search(len(s.shares), func(i int) bool { return s.shares[i].After(cutoff) })
+	s.shares = append(s.shares[:0], s.shares[i:]...)
+}
+
 // makeWork creates a work package for external miner.
 //
 // The work package consists of 3 strings:
@@ -359,6 +629,14 @@ func (s *remoteSealer) loop() {
 func (s *remoteSealer) makeWork(block *types.Block) {
 	hash := s.inihash.SealHash(block.Header())
 
+	// Work on a new parent invalidates the solutions searched for the old one
+	s.currentClean = s.currentBlock == nil || s.currentBlock.ParentHash() != block.ParentHash()
+	if s.currentClean {
+		// Solutions sealed before the head changed may have been reorged out, so
+		// they need to be sealed again if resubmitted.
+		s.solved = make(map[common.Hash]struct{})
+	}
+
 	s.currentWork[0] = hash.Hex()
 	//s.currentWork[1] = common.BytesToHash(SeedHash(block.NumberU64())).Hex()
 	s.currentWork[1] = common.BytesToHash(new(big.Int).Div(two256, block.Difficulty()).Bytes()).Hex()
@@ -369,6 +647,48 @@ func (s *remoteSealer) makeWork(block *types.Block) {
 	// Trace the seal work fetched by remote sealer.
 	s.currentBlock = block
 	s.works[hash] = block
+	if _, ok := s.workTimes[hash]; !ok {
+		s.workTimes[hash] = time.Now()
+		s.trackRecent()
+	}
+}
+
+// trackRecent adds the current work package to the ring buffer of recent work.
+func (s *remoteSealer) trackRecent() {
+	s.recentWork[s.recentHead] = *s.workDetails()
+	s.recentHead = (s.recentHead + 1) % maxRecentWork
+	if s.recentCount < maxRecentWork {
+		s.recentCount++
+	}
+}
+
+// recentWorks returns the retained work packages, newest first.
+func (s *remoteSealer) recentWorks() []WorkDetails {
+	works := make([]WorkDetails, 0, s.recentCount)
+	for i := 1; i <= s.recentCount; i++ {
+		works = append(works, s.recentWork[(s.recentHead-i+maxRecentWork)%maxRecentWork])
+	}
+	return works
+}
+
+// pendingWork returns the work packages that are still accepted for submission,
+// ordered by block number. Stale packages not yet reaped are omitted.
+func (s *remoteSealer) pendingWork() []PendingWork {
+	pending := make([]PendingWork, 0, len(s.works))
+	for hash, block := range s.works {
+		if block.NumberU64()+staleThreshold <= s.currentBlock.NumberU64() {
+			continue
+		}
+		pending = append(pending, PendingWork{
+			Hash:   hash,
+			Number: hexutil.Uint64(block.NumberU64()),
+			Age:    hexutil.Uint64(time.Since(s.workTimes[hash]) / time.Second),
+		})
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].Number < pending[j].Number
+	})
+	return pending
 }
 
 // notifyWork notifies all the specified mining endpoints of the availability of
@@ -413,18 +733,51 @@ func (s *remoteSealer) sendNotification(ctx context.Context, url string, json []
 	}
 }
 
+// trackSubmitted remembers a block sealed from a remote submission, so that its
+// acceptance into the chain can be reported back to the miners. Blocks older than
+// the retention window are forgotten.
+func (s *remoteSealer) trackSubmitted(block *types.Block) {
+	s.submitLock.Lock()
+	defer s.submitLock.Unlock()
+
+	number := block.NumberU64()
+	for hash, n := range s.submitted {
+		if n+submittedRetention < number {
+			delete(s.submitted, hash)
+		}
+	}
+	s.submitted[block.Hash()] = number
+}
+
+// isSubmitted reports whether the block was sealed from a remote submission.
+func (s *remoteSealer) isSubmitted(hash common.Hash) bool {
+	s.submitLock.Lock()
+	defer s.submitLock.Unlock()
+
+	_, ok := s.submitted[hash]
+	return ok
+}
+
 // submitWork verifies the submitted pow solution, returning
 // whether the solution was accepted or not (not can be both a bad pow as well as
 // any other error, like no pending work or stale mining result).
-func (s *remoteSealer) submitWork(nonce types.BlockNonce, extraNonce types.BlockNonce, sealhash common.Hash) bool {
+func (s *remoteSealer) submitWork(nonce types.BlockNonce, extraNonce types.BlockNonce, sealhash common.Hash, coinbase *common.Address) bool {
 	if s.currentBlock == nil {
 		s.inihash.config.Log.Error("Pending work without block", "sealhash", sealhash)
+		s.stats.Stale++
 		return false
 	}
 	// Make sure the work submitted is present
 	block := s.works[sealhash]
 	if block == nil {
 		s.inihash.config.Log.Warn("Work submitted but none pending", "sealhash", sealhash, "curnumber", s.currentBlock.NumberU64())
+		s.stats.Stale++
+		return false
+	}
+	// The coinbase is sealed into the work, make sure the miner mined for it
+	if coinbase != nil && *coinbase != block.Coinbase() {
+		s.inihash.config.Log.Warn("Work submitted for foreign coinbase", "sealhash", sealhash, "coinbase", *coinbase, "want", block.Coinbase())
+		s.stats.Invalid++
 		return false
 	}
 	// Verify the correctness of submitted result.
@@ -436,31 +789,47 @@ func (s *remoteSealer) submitWork(nonce types.BlockNonce, extraNonce types.Block
 	if !s.noverify {
 		if err := s.inihash.verifySeal(nil, header, true); err != nil {
 			s.inihash.config.Log.Warn("Invalid proof-of-work submitted", "sealhash", sealhash, "elapsed", common.PrettyDuration(time.Since(start)), "err", err)
+			s.stats.Invalid++
 			return false
 		}
 	}
 	// Make sure the result channel is assigned.
 	if s.results == nil {
 		s.inihash.config.Log.Warn("Inihash result channel is empty, submitted mining result is rejected")
+		s.stats.Stale++
 		return false
 	}
 	s.inihash.config.Log.Trace("Verified correct proof-of-work", "sealhash", sealhash, "elapsed", common.PrettyDuration(time.Since(start)))
 
 	// Solutions seems to be valid, return to the miner and notify acceptance.
 	solution := block.WithSeal(header)
+	if _, ok := s.solved[solution.Hash()]; ok {
+		// The solution is valid and already sealed, so the miner did its job; only
+		// skip handing the same block to the miner again.
+		s.inihash.config.Log.Debug("Work submitted is a duplicate", "number", solution.NumberU64(), "sealhash", sealhash, "hash", solution.Hash())
+		s.stats.Duplicate++
+		return true
+	}
+	// Resubmitting a solution must not inflate the share rate
+	s.shares = append(s.shares, time.Now())
 
 	// The submitted solution is within the scope of acceptance.
 	if solution.NumberU64()+staleThreshold > s.currentBlock.NumberU64() {
 		select {
 		case s.results <- solution:
-			s.inihash.config.Log.Debug("Work submitted is acceptable", "number", solution.NumberU64(), "sealhash", sealhash, "hash", solution.Hash())
+			s.inihash.config.Log.Debug("Work submitted is acceptable", "number", solution.NumberU64(), "sealhash", sealhash, "hash", solution.Hash(), "coinbase", solution.Coinbase())
+			s.solved[solution.Hash()] = struct{}{}
+			s.trackSubmitted(solution)
+			s.stats.Accepted++
 			return true
 		default:
 			s.inihash.config.Log.Warn("Sealing result is not read by miner", "mode", "remote", "sealhash", sealhash)
+			s.stats.Stale++
 			return false
 		}
 	}
 	// The submitted block is too old to accept, drop it.
 	s.inihash.config.Log.Warn("Work submitted is too old", "number", solution.NumberU64(), "sealhash", sealhash, "hash", solution.Hash())
+	s.stats.Stale++
 	return false
 }
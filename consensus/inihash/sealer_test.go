@@ -18,6 +18,7 @@ package inihash
 
 import (
 	"encoding/json"
+	"errors"
 	"io/ioutil"
 	"math/big"
 	"net/http"
@@ -217,7 +218,7 @@ func TestRemoteMultiNotifyFull(t *testing.T) {
 func TestStaleSubmission(t *testing.T) {
 	ethash := NewTester(nil, true)
 	defer ethash.Close()
-	api := &API{ethash}
+	api := &API{inihash: ethash}
 
 	fakeNonce, fakeDigest := types.BlockNonce{0x01, 0x02, 0x03}, common.HexToHash("deadbeef")
 
@@ -296,3 +297,166 @@ func TestStaleSubmission(t *testing.T) {
 		}
 	}
 }
+
+// Tests that the outcomes of submitted solutions are told apart in the stats.
+func TestSubmitStats(t *testing.T) {
+	ethash := NewTester(nil, false)
+	defer ethash.Close()
+	api := &API{inihash: ethash}
+
+	results := make(chan *types.Block, 16)
+	easy := &types.Header{ParentHash: common.BytesToHash([]byte{0xa}), Number: big.NewInt(1), Difficulty: big.NewInt(1)}
+	ethash.Seal(nil, types.NewBlockWithHeader(easy), results, nil)
+	hard := &types.Header{ParentHash: common.BytesToHash([]byte{0xa}), Number: big.NewInt(1), Difficulty: new(big.Int).Lsh(big1, 255)}
+	ethash.Seal(nil, types.NewBlockWithHeader(hard), results, nil)
+
+	api.SubmitWork(types.BlockNonce{}, types.BlockNonce{}, ethash.SealHash(easy)) // accepted
+	api.SubmitWork(types.BlockNonce{}, types.BlockNonce{}, ethash.SealHash(easy)) // duplicate
+	api.SubmitWork(types.BlockNonce{}, types.BlockNonce{}, ethash.SealHash(hard)) // invalid
+	api.SubmitWork(types.BlockNonce{}, types.BlockNonce{}, common.Hash{0xff})     // stale
+
+	stats, err := api.GetSubmitStats()
+	if err != nil {
+		t.Fatalf("failed to retrieve stats: %v", err)
+	}
+	if want := (SubmitStats{Accepted: 1, Stale: 1, Duplicate: 1, Invalid: 1}); stats != want {
+		t.Errorf("stats mismatch: have %+v, want %+v", stats, want)
+	}
+	// Only the accepted solution counts as a share, the duplicate must not
+	rate, err := api.GetSharesPerSecond()
+	if err != nil {
+		t.Fatalf("failed to retrieve share rate: %v", err)
+	}
+	if want := 1 / defaultShareRateWindow.Seconds(); rate != want {
+		t.Errorf("share rate mismatch: have %v, want %v", rate, want)
+	}
+}
+
+// Tests that a duplicate solution is acknowledged without being sealed again, but
+// that it is sealed anew if resubmitted after the head changed, e.g. by a reorg.
+func TestResubmitAfterReorg(t *testing.T) {
+	ethash := NewTester(nil, false)
+	defer ethash.Close()
+	api := &API{inihash: ethash}
+
+	results := make(chan *types.Block, 16)
+	header := &types.Header{ParentHash: common.BytesToHash([]byte{0xa}), Number: big.NewInt(1), Difficulty: big.NewInt(1)}
+	ethash.Seal(nil, types.NewBlockWithHeader(header), results, nil)
+
+	submit := func(want bool) {
+		t.Helper()
+		if !api.SubmitWork(types.BlockNonce{}, types.BlockNonce{}, ethash.SealHash(header)) {
+			t.Fatalf("solution rejected")
+		}
+		select {
+		case block := <-results:
+			if !want {
+				t.Fatalf("duplicate solution sealed again: %x", block.Hash())
+			}
+			if !ethash.remote.isSubmitted(block.Hash()) {
+				t.Errorf("sealed block %x not tracked for the subscription", block.Hash())
+			}
+		case <-time.After(100 * time.Millisecond):
+			if want {
+				t.Fatalf("solution not sealed")
+			}
+		}
+	}
+	submit(true)
+	submit(false)
+
+	// Switch the work to a different parent, the old solution is still valid
+	reorg := &types.Header{ParentHash: common.BytesToHash([]byte{0xb}), Number: big.NewInt(1), Difficulty: big.NewInt(1)}
+	ethash.Seal(nil, types.NewBlockWithHeader(reorg), results, nil)
+	submit(true)
+
+	stats, err := api.GetSubmitStats()
+	if err != nil {
+		t.Fatalf("failed to retrieve stats: %v", err)
+	}
+	if want := (SubmitStats{Accepted: 2, Duplicate: 1}); stats != want {
+		t.Errorf("stats mismatch: have %+v, want %+v", stats, want)
+	}
+}
+
+// Tests that the hash rate history wraps around once full, returning the newest
+// samples oldest first.
+func TestHashrateHistory(t *testing.T) {
+	s := &remoteSealer{}
+	if history := s.hashrateHistory(); len(history) != 0 {
+		t.Fatalf("empty history has %d samples", len(history))
+	}
+	start := time.Unix(1000, 0)
+	for i := 0; i < hashrateHistorySize+10; i++ {
+		s.trackHashrate(start.Add(time.Duration(i)*time.Second), uint64(i))
+	}
+	history := s.hashrateHistory()
+	if len(history) != hashrateHistorySize {
+		t.Fatalf("history length mismatch: have %d, want %d", len(history), hashrateHistorySize)
+	}
+	for i, point := range history {
+		if want := uint64(i + 10); point.Rate != want || point.Timestamp != 1000+int64(want) {
+			t.Fatalf("sample %d mismatch: have %+v, want rate %d", i, point, want)
+		}
+	}
+	// Make sure the API caps the samples returned
+	ethash := NewTester(nil, false)
+	defer ethash.Close()
+	api := &API{inihash: ethash}
+
+	if history := api.GetHashrateHistory(0); history != nil {
+		t.Errorf("non-positive sample count returned %v", history)
+	}
+	if history := api.GetHashrateHistory(10); len(history) != 0 {
+		t.Errorf("fresh sealer returned %d samples", len(history))
+	}
+}
+
+// Tests that fetching work from a wedged sealer gives up after the deadline.
+func TestGetWorkTimeout(t *testing.T) {
+	ethash := &Inihash{config: Config{WorkTimeout: 50 * time.Millisecond}}
+	ethash.remote = &remoteSealer{
+		inihash:     ethash,
+		fetchWorkCh: make(chan *sealWork),
+		exitCh:      make(chan struct{}),
+	}
+	api := &API{inihash: ethash}
+
+	start := time.Now()
+	if _, err := api.GetWork(); err != errWorkTimeout {
+		t.Fatalf("error mismatch: have %v, want %v", err, errWorkTimeout)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("deadline overshot: took %v", elapsed)
+	}
+}
+
+// Tests that asynchronous submissions deliver their outcome on the returned channel.
+func TestSubmitWorkAsync(t *testing.T) {
+	ethash := NewTester(nil, true)
+
+	results := make(chan *types.Block, 1)
+	header := &types.Header{ParentHash: common.BytesToHash([]byte{0xa}), Number: big.NewInt(1), Difficulty: big.NewInt(100)}
+	ethash.Seal(nil, types.NewBlockWithHeader(header), results, nil)
+
+	for i, tt := range []struct {
+		hash  common.Hash
+		valid bool
+	}{
+		{ethash.SealHash(header), true},
+		{common.Hash{0xff}, false},
+	} {
+		select {
+		case err := <-ethash.SubmitWorkAsync(types.BlockNonce{}, types.BlockNonce{}, tt.hash):
+			if (err == nil) != tt.valid {
+				t.Errorf("submission %d: outcome mismatch: have %v, want valid %t", i, err, tt.valid)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("submission %d: outcome timeout", i)
+		}
+	}
+	ethash.Close()
+	if err := <-ethash.SubmitWorkAsync(types.BlockNonce{}, types.BlockNonce{}, ethash.SealHash(header)); !errors.Is(err, ErrSealerClosing) {
+		t.Errorf("error mismatch: have %v, want %v", err, ErrSealerClosing)
+	}
+}
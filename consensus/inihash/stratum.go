@@ -0,0 +1,376 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package inihash
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"PureChain/common"
+	"PureChain/common/hexutil"
+	"PureChain/core/types"
+	"PureChain/event"
+)
+
+const (
+	// stratumProtocol is the protocol version announced to subscribing miners.
+	stratumProtocol = "EthereumStratum/1.0.0"
+
+	// stratumReadTimeout is the time a miner may stay silent before it's dropped.
+	stratumReadTimeout = 5 * time.Minute
+
+	// stratumWriteTimeout is the time allowed to deliver a message to a miner.
+	stratumWriteTimeout = 10 * time.Second
+
+	// stratumMaxLine is the maximum size of a single request sent by a miner.
+	stratumMaxLine = 16 * 1024
+)
+
+var (
+	errStratumNotSubscribed = errors.New("not subscribed")
+	errStratumBadParams     = errors.New("invalid parameters")
+	errStratumUnknownMethod = errors.New("method not found")
+)
+
+// stratumRequest is a JSON-RPC request sent by a miner over a stratum connection.
+type stratumRequest struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params []string        `json:"params"`
+}
+
+// stratumResponse is the reply to a stratumRequest.
+type stratumResponse struct {
+	ID     json.RawMessage `json:"id"`
+	Result interface{}     `json:"result"`
+	Error  interface{}     `json:"error"`
+}
+
+// stratumNotification is a message pushed to a miner without being requested.
+type stratumNotification struct {
+	ID     interface{}   `json:"id"`
+	Method string        `json:"method"`
+	Params []interface{} `json:"params"`
+}
+
+// StratumServer hands out the work of the remote sealer to miners speaking the
+// stratum protocol over TCP. Instead of polling, subscribed miners are pushed
+// every new work package as mining.notify, while their mining.submit solutions
+// are fed into the same verification path as the ones submitted over RPC.
+type StratumServer struct {
+	inihash *Inihash
+	api     *API
+	addr    string
+
+	listener net.Listener
+	works    chan *WorkDetails
+	sub      event.Subscription
+
+	sessions map[*stratumSession]struct{}
+	nextID   uint64
+	lock     sync.Mutex
+
+	wg   sync.WaitGroup
+	quit chan struct{}
+}
+
+// stratumSession is a single miner connected to the stratum server.
+type stratumSession struct {
+	id         uint64
+	conn       net.Conn
+	subscribed int32 // Whether the miner asked to be notified of new work (atomic)
+	writeLock  sync.Mutex
+}
+
+// NewStratumServer creates a stratum server for the remote sealer of the given
+// inihash engine, listening on the given TCP address once started.
+func NewStratumServer(inihash *Inihash, addr string) *StratumServer {
+	return &StratumServer{
+		inihash:  inihash,
+		api:      &API{inihash: inihash},
+		addr:     addr,
+		works:    make(chan *WorkDetails, 16),
+		sessions: make(map[*stratumSession]struct{}),
+		quit:     make(chan struct{}),
+	}
+}
+
+// Start opens the listening socket and starts serving miners.
+func (s *StratumServer) Start() error {
+	if s.inihash.remote == nil {
+		return errors.New("remote sealer not running")
+	}
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	s.listener = listener
+	s.sub = s.inihash.remote.subscribeWork(s.works)
+
+	s.wg.Add(2)
+	go s.acceptLoop()
+	go s.notifyLoop()
+
+	s.inihash.config.Log.Info("Stratum server started", "addr", listener.Addr())
+	return nil
+}
+
+// Addr returns the address the server is listening on, nil if not started.
+func (s *StratumServer) Addr() net.Addr {
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+// Stop closes the listening socket and disconnects all miners.
+func (s *StratumServer) Stop() {
+	if s.listener == nil {
+		return
+	}
+	close(s.quit)
+	s.listener.Close()
+	s.sub.Unsubscribe()
+
+	s.lock.Lock()
+	for session := range s.sessions {
+		session.conn.Close()
+	}
+	s.lock.Unlock()
+
+	s.wg.Wait()
+	s.inihash.config.Log.Info("Stratum server stopped", "addr", s.listener.Addr())
+}
+
+// acceptLoop accepts incoming miner connections until the server is stopped.
+func (s *StratumServer) acceptLoop() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			select {
+			case <-s.quit:
+				return
+			default:
+			}
+			if netErr, ok := err.(net.Error); ok && netErr.Temporary() {
+				time.Sleep(100 * time.Millisecond)
+				continue
+			}
+			s.inihash.config.Log.Warn("Stratum server failed to accept", "err", err)
+			return
+		}
+		s.lock.Lock()
+		s.nextID++
+		session := &stratumSession{id: s.nextID, conn: conn}
+		s.sessions[session] = struct{}{}
+		s.lock.Unlock()
+
+		s.wg.Add(1)
+		go s.serve(session)
+	}
+}
+
+// notifyLoop pushes every new work package to the subscribed miners.
+func (s *StratumServer) notifyLoop() {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case work := <-s.works:
+			if s.inihash.Paused() {
+				continue
+			}
+			s.lock.Lock()
+			sessions := make([]*stratumSession, 0, len(s.sessions))
+			for session := range s.sessions {
+				if atomic.LoadInt32(&session.subscribed) == 1 {
+					sessions = append(sessions, session)
+				}
+			}
+			s.lock.Unlock()
+
+			for _, session := range sessions {
+				if err := session.notify(work); err != nil {
+					s.inihash.config.Log.Debug("Failed to notify stratum miner", "session", session.id, "err", err)
+					session.conn.Close()
+				}
+			}
+		case <-s.sub.Err():
+			return
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// serve handles the requests of a single miner until it disconnects.
+func (s *StratumServer) serve(session *stratumSession) {
+	defer func() {
+		s.lock.Lock()
+		delete(s.sessions, session)
+		s.lock.Unlock()
+
+		session.conn.Close()
+		s.wg.Done()
+	}()
+	scanner := bufio.NewScanner(session.conn)
+	scanner.Buffer(make([]byte, 0, 1024), stratumMaxLine)
+
+	for {
+		session.conn.SetReadDeadline(time.Now().Add(stratumReadTimeout))
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				s.inihash.config.Log.Debug("Stratum miner disconnected", "session", session.id, "err", err)
+			}
+			return
+		}
+		var req stratumRequest
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			s.inihash.config.Log.Debug("Invalid stratum request", "session", session.id, "err", err)
+			return
+		}
+		result, err := s.handle(session, &req)
+
+		res := &stratumResponse{ID: req.ID, Result: result}
+		if err != nil {
+			res.Result, res.Error = false, err.Error()
+		}
+		if err := session.write(res); err != nil {
+			return
+		}
+		// A freshly subscribed miner gets the current work straight away
+		if req.Method == "mining.subscribe" && err == nil {
+			if work, err := s.api.GetWorkDetailed(); err == nil {
+				if err := session.notify(work); err != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+// handle executes a single stratum request, returning its result.
+func (s *StratumServer) handle(session *stratumSession, req *stratumRequest) (interface{}, error) {
+	switch req.Method {
+	case "mining.subscribe":
+		atomic.StoreInt32(&session.subscribed, 1)
+		return []interface{}{
+			[]string{"mining.notify", fmt.Sprintf("%016x", session.id), stratumProtocol},
+			"",
+		}, nil
+
+	case "mining.authorize":
+		// Rewards are paid to the coinbase sealed into the work, workers
+		// are only named for bookkeeping.
+		return true, nil
+
+	case "mining.submit":
+		// Params are the worker name, job id (seal hash), nonce and extra nonce
+		if atomic.LoadInt32(&session.subscribed) == 0 {
+			return nil, errStratumNotSubscribed
+		}
+		if len(req.Params) != 4 {
+			return nil, errStratumBadParams
+		}
+		hash, err := decodeStratumHash(req.Params[1])
+		if err != nil {
+			return nil, err
+		}
+		nonce, err := decodeStratumNonce(req.Params[2])
+		if err != nil {
+			return nil, err
+		}
+		extraNonce, err := decodeStratumNonce(req.Params[3])
+		if err != nil {
+			return nil, err
+		}
+		if !s.api.SubmitWork(nonce, extraNonce, hash) {
+			return nil, errInvalidSealResult
+		}
+		return true, nil
+
+	case "mining.hashrate":
+		// Params are the hash rate and the miner's unique id
+		if len(req.Params) != 2 {
+			return nil, errStratumBadParams
+		}
+		rate, err := hexutil.DecodeUint64(req.Params[0])
+		if err != nil {
+			return nil, errStratumBadParams
+		}
+		id, err := decodeStratumHash(req.Params[1])
+		if err != nil {
+			return nil, err
+		}
+		return s.api.SubmitHashrate(hexutil.Uint64(rate), id), nil
+
+	default:
+		return nil, errStratumUnknownMethod
+	}
+}
+
+// notify pushes a work package to the miner. The params are the job id (seal
+// hash), seed hash, target, block number, timestamp and whether in-flight jobs
+// must be dropped.
+func (session *stratumSession) notify(work *WorkDetails) error {
+	return session.write(&stratumNotification{
+		Method: "mining.notify",
+		Params: []interface{}{work.Hash, work.SeedHash, work.Target, work.Number, work.Timestamp, work.Clean},
+	})
+}
+
+// write sends a single newline delimited message to the miner.
+func (session *stratumSession) write(msg interface{}) error {
+	blob, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	session.writeLock.Lock()
+	defer session.writeLock.Unlock()
+
+	session.conn.SetWriteDeadline(time.Now().Add(stratumWriteTimeout))
+	_, err = session.conn.Write(append(blob, '\n'))
+	return err
+}
+
+// decodeStratumHash parses a hex encoded 32 byte hash sent by a miner.
+func decodeStratumHash(input string) (common.Hash, error) {
+	blob, err := hexutil.Decode(input)
+	if err != nil || len(blob) != common.HashLength {
+		return common.Hash{}, errStratumBadParams
+	}
+	return common.BytesToHash(blob), nil
+}
+
+// decodeStratumNonce parses a hex encoded 8 byte nonce sent by a miner.
+func decodeStratumNonce(input string) (types.BlockNonce, error) {
+	blob, err := hexutil.Decode(input)
+	if err != nil || len(blob) != len(types.BlockNonce{}) {
+		return types.BlockNonce{}, errStratumBadParams
+	}
+	var nonce types.BlockNonce
+	copy(nonce[:], blob)
+	return nonce, nil
+}
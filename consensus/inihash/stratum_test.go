@@ -0,0 +1,86 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package inihash
+
+import (
+	"bufio"
+	"encoding/json"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"PureChain/core/types"
+)
+
+// Tests that subscribed stratum miners are pushed new work and that invalid
+// solutions are rejected.
+func TestStratumServer(t *testing.T) {
+	ethash := NewTester(nil, false)
+	defer ethash.Close()
+
+	server := NewStratumServer(ethash, "127.0.0.1:0")
+	if err := server.Start(); err != nil {
+		t.Fatalf("failed to start stratum server: %v", err)
+	}
+	defer server.Stop()
+
+	conn, err := net.Dial("tcp", server.Addr().String())
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	reader := bufio.NewReader(conn)
+
+	read := func() map[string]interface{} {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			t.Fatalf("failed to read message: %v", err)
+		}
+		var msg map[string]interface{}
+		if err := json.Unmarshal(line, &msg); err != nil {
+			t.Fatalf("invalid message %q: %v", line, err)
+		}
+		return msg
+	}
+	conn.Write([]byte(`{"id":1,"method":"mining.subscribe","params":["test"]}` + "\n"))
+	if msg := read(); msg["error"] != nil {
+		t.Fatalf("subscription failed: %v", msg["error"])
+	}
+	// Push new work and make sure it's delivered
+	header := &types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(100)}
+	sealhash := ethash.SealHash(header)
+	ethash.Seal(nil, types.NewBlockWithHeader(header), make(chan *types.Block), nil)
+
+	msg := read()
+	if msg["method"] != "mining.notify" {
+		t.Fatalf("method mismatch: have %v, want mining.notify", msg["method"])
+	}
+	params := msg["params"].([]interface{})
+	if params[0] != sealhash.Hex() {
+		t.Errorf("job id mismatch: have %v, want %v", params[0], sealhash.Hex())
+	}
+	if params[5] != true {
+		t.Errorf("first job not marked clean")
+	}
+	// Submit a bogus solution and make sure it's rejected
+	conn.Write([]byte(`{"id":2,"method":"mining.submit","params":["test","` + sealhash.Hex() + `","0x0000000000000000","0x0000000000000000"]}` + "\n"))
+	if msg := read(); msg["error"] == nil {
+		t.Errorf("invalid solution accepted")
+	}
+}
@@ -17,14 +17,62 @@
 package core
 
 import (
+	"PureChain/common"
 	"PureChain/consensus"
 	"PureChain/core/state"
 	"PureChain/core/types"
+	"PureChain/event"
+	"PureChain/log"
+	"PureChain/metrics"
 	"PureChain/params"
 	"PureChain/trie"
+	"context"
+	"errors"
 	"fmt"
+	"math/big"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// maxUncleDepth is the maximum number of blocks an uncle may precede the block
+// including it.
+const maxUncleDepth = 6
+
+// maxInvalidBlocks is the number of most recent blocks rejected by state
+// validation that are retained for inspection.
+const maxInvalidBlocks = 32
+
+var (
+	txRootTimer    = metrics.NewRegisteredTimer("chain/validation/txroot", nil)
+	bloomTimer     = metrics.NewRegisteredTimer("chain/validation/bloom", nil)
+	receiptsTimer  = metrics.NewRegisteredTimer("chain/validation/receipts", nil)
+	stateRootTimer = metrics.NewRegisteredTimer("chain/validation/stateroot", nil)
 )
 
+// stackTriePool recycles the stack tries hashing the transaction and receipt
+// lists of validated blocks.
+var stackTriePool = sync.Pool{
+	New: func() interface{} {
+		return trie.NewStackTrie(nil)
+	},
+}
+
+// deriveSha computes the root hash of the given list with a pooled stack trie.
+// Every caller gets its own trie, so it's safe to use from concurrent checks.
+func deriveSha(list types.DerivableList) common.Hash {
+	st := stackTriePool.Get().(*trie.StackTrie)
+	defer func() {
+		st.Reset()
+		stackTriePool.Put(st)
+	}()
+	return types.DeriveSha(list, st)
+}
+
 // BlockValidator is responsible for validating block headers, uncles and
 // processed state.
 //
@@ -33,6 +81,40 @@ type BlockValidator struct {
 	config *params.ChainConfig // Chain configuration options
 	bc     *BlockChain         // Canonical block chain
 	engine consensus.Engine    // Consensus engine used for validating
+
+	nonceWindow  uint64     // Number of blocks to look back for reused nonces (0 = disabled)
+	nonceReject  bool       // Whether to reject blocks reusing a nonce instead of logging
+	recentNonces *lru.Cache // Block number and hash of recently seen nonces
+	nonceLock    sync.Mutex // Protects the nonce reuse diagnostic fields
+
+	timeout    int64 // Maximum duration of state validation in nanoseconds (0 = unlimited), accessed atomically
+	dumpOnRoot int32 // Whether the transactions of blocks with a mismatching state root are logged, accessed atomically
+
+	lastErr     *ValidationError   // Most recent block validation failure
+	invalid     []InvalidBlockInfo // Most recent blocks rejected by state validation, newest first
+	lastErrLock sync.Mutex         // Protects lastErr and invalid
+
+	failureFeed event.Feed // Announces the blocks failing state validation
+
+	local     map[*types.Block]struct{} // Locally sealed blocks being imported, by identity
+	localLock sync.Mutex                // Protects local
+}
+
+// InvalidBlockInfo describes a block rejected by state validation.
+type InvalidBlockInfo struct {
+	Hash   common.Hash `json:"hash"`
+	Number uint64      `json:"number"`
+	Kind   string      `json:"kind"` // Check the block failed, see ValidationFailure
+	Reason string      `json:"reason"`
+	Time   time.Time   `json:"time"`
+}
+
+// ValidationError describes a block that failed body or state validation.
+type ValidationError struct {
+	Number uint64      `json:"number"`
+	Hash   common.Hash `json:"hash"`
+	Reason string      `json:"reason"`
+	Time   time.Time   `json:"time"`
 }
 
 // NewBlockValidator returns a new block validator which is safe for re-use
@@ -45,35 +127,266 @@ func NewBlockValidator(config *params.ChainConfig, blockchain *BlockChain, engin
 	return validator
 }
 
+// SetNonceReuseCheck configures the diagnostic flagging blocks that reuse a nonce
+// seen on a different block within the last window blocks. Reusing a nonce is
+// legitimate in proof-of-work, so offenders are only logged unless reject is set.
+// A zero window disables the check.
+func (v *BlockValidator) SetNonceReuseCheck(window uint64, reject bool) {
+	v.nonceLock.Lock()
+	defer v.nonceLock.Unlock()
+
+	v.nonceWindow, v.nonceReject, v.recentNonces = window, reject, nil
+	if window > 0 {
+		v.recentNonces, _ = lru.New(int(window))
+	}
+}
+
+// SetValidationTimeout bounds how long state validation, including the state root
+// recomputation, may take before the block is rejected with ErrValidationTimeout.
+// A zero timeout waits indefinitely.
+func (v *BlockValidator) SetValidationTimeout(timeout time.Duration) {
+	atomic.StoreInt64(&v.timeout, int64(timeout))
+}
+
+// validationTimeout returns the configured state validation deadline.
+func (v *BlockValidator) validationTimeout() time.Duration {
+	return time.Duration(atomic.LoadInt64(&v.timeout))
+}
+
+// SetDumpOnStateMismatch toggles logging the transactions and coinbase of blocks
+// failing validation with a mismatching state root, to help tracking down state
+// divergences. Encoding the transactions is expensive, so it's off by default.
+func (v *BlockValidator) SetDumpOnStateMismatch(enabled bool) {
+	var dump int32
+	if enabled {
+		dump = 1
+	}
+	atomic.StoreInt32(&v.dumpOnRoot, dump)
+}
+
+// LastValidationError returns the most recent block validation failure, or nil
+// if no block failed validation since the last clearing.
+func (v *BlockValidator) LastValidationError() *ValidationError {
+	v.lastErrLock.Lock()
+	defer v.lastErrLock.Unlock()
+
+	if v.lastErr == nil {
+		return nil
+	}
+	failure := *v.lastErr
+	return &failure
+}
+
+// ClearLastValidationError forgets the most recent block validation failure.
+func (v *BlockValidator) ClearLastValidationError() {
+	v.lastErrLock.Lock()
+	defer v.lastErrLock.Unlock()
+
+	v.lastErr = nil
+}
+
+// recordFailure retains the validation error of the block, unless it only means
+// the block is already known or can't be processed yet.
+func (v *BlockValidator) recordFailure(block *types.Block, err error) {
+	if err == nil || err == ErrKnownBlock || err == consensus.ErrUnknownAncestor || err == consensus.ErrPrunedAncestor {
+		return
+	}
+	if errors.Is(err, consensus.ErrFutureBlock) {
+		return // Not invalid, the block is queued until its time comes
+	}
+	v.lastErrLock.Lock()
+	defer v.lastErrLock.Unlock()
+
+	v.lastErr = &ValidationError{
+		Number: block.NumberU64(),
+		Hash:   block.Hash(),
+		Reason: err.Error(),
+		Time:   time.Now(),
+	}
+}
+
+// LastInvalidBlocks returns the most recent blocks rejected by state validation,
+// newest first. At most 32 blocks are retained.
+func (v *BlockValidator) LastInvalidBlocks() []InvalidBlockInfo {
+	v.lastErrLock.Lock()
+	defer v.lastErrLock.Unlock()
+
+	return append([]InvalidBlockInfo(nil), v.invalid...)
+}
+
+// recordInvalid retains the state validation failure, dropping the oldest one
+// if the limit is reached.
+func (v *BlockValidator) recordInvalid(failure ValidationFailure) {
+	v.lastErrLock.Lock()
+	defer v.lastErrLock.Unlock()
+
+	if len(v.invalid) >= maxInvalidBlocks {
+		v.invalid = v.invalid[:maxInvalidBlocks-1]
+	}
+	v.invalid = append([]InvalidBlockInfo{{
+		Hash:   failure.BlockHash,
+		Number: failure.Number,
+		Kind:   failure.Kind,
+		Reason: failure.Err.Error(),
+		Time:   time.Now(),
+	}}, v.invalid...)
+}
+
+// SubscribeValidationFailures registers a subscription of ValidationFailure,
+// posted whenever a block fails state validation.
+func (v *BlockValidator) SubscribeValidationFailures(ch chan<- ValidationFailure) event.Subscription {
+	return v.failureFeed.Subscribe(ch)
+}
+
+// validationFailureKind names the check of the state validation err stems from.
+func validationFailureKind(err error) string {
+	var stateErr *StateValidationError
+	switch {
+	case errors.As(err, &stateErr):
+		return stateErr.Kind.String()
+	case errors.Is(err, ErrGasUsedExceedsLimit):
+		return "gasLimit"
+	case errors.Is(err, ErrCumulativeGasDecreased):
+		return ValidationCumulativeGas.String()
+	case errors.Is(err, ErrValidationTimeout):
+		return "timeout"
+	default:
+		return "other"
+	}
+}
+
+// ValidateReorg checks that a reorg from the given common ancestor up to the given
+// head doesn't rewrite more blocks than the chain allows. The head is the tip of
+// the longer of the chains being switched between.
+func (v *BlockValidator) ValidateReorg(ancestor uint64, head uint64) error {
+	return validateReorgDepth(v.config, ancestor, head)
+}
+
+// validateReorgDepth checks that a reorg from the given common ancestor up to the
+// given head doesn't rewrite more blocks than the chain configuration allows.
+func validateReorgDepth(config *params.ChainConfig, ancestor uint64, head uint64) error {
+	limit := config.MaxReorgDepth
+	if limit == 0 || head <= ancestor {
+		return nil
+	}
+	if depth := head - ancestor; depth > limit {
+		return fmt.Errorf("%w: %d blocks above #%d > %d", ErrReorgTooDeep, depth, ancestor, limit)
+	}
+	return nil
+}
+
+// trustLocal marks the given block as sealed locally until the returned function
+// is called. Blocks are tracked by identity rather than hash, so a block decoded
+// from the network never matches, even if it claims the same header.
+func (v *BlockValidator) trustLocal(block *types.Block) func() {
+	v.localLock.Lock()
+	defer v.localLock.Unlock()
+
+	if v.local == nil {
+		v.local = make(map[*types.Block]struct{})
+	}
+	v.local[block] = struct{}{}
+
+	return func() {
+		v.localLock.Lock()
+		defer v.localLock.Unlock()
+
+		delete(v.local, block)
+	}
+}
+
+// isLocal reports whether the block is being imported as sealed locally.
+func (v *BlockValidator) isLocal(block *types.Block) bool {
+	v.localLock.Lock()
+	defer v.localLock.Unlock()
+
+	_, ok := v.local[block]
+	return ok
+}
+
 // ValidateBody validates the given block's uncles and verifies the block
 // header's transaction and uncle roots. The headers are assumed to be already
 // validated at this point.
 func (v *BlockValidator) ValidateBody(block *types.Block) error {
+	err := v.validateBody(block, false)
+	v.recordFailure(block, err)
+	return err
+}
+
+// validateBody runs the body checks of the block. Candidate blocks are validated
+// without being imported, so they aren't rejected for being known already.
+func (v *BlockValidator) validateBody(block *types.Block, candidate bool) error {
+	// Make sure the cached hash wasn't left stale by mutating the header
+	header := block.Header()
+	if hash := header.Hash(); hash != block.Hash() {
+		return fmt.Errorf("%w: cached %x, computed %x", ErrHeaderHashMismatch, block.Hash(), hash)
+	}
 	// Check whether the block's known, and if not, that it's linkable
-	if v.bc.HasBlockAndState(block.Hash(), block.NumberU64()) {
+	if !candidate && v.bc.HasBlockAndState(block.Hash(), block.NumberU64()) {
 		return ErrKnownBlock
 	}
-	// Header validity is known at this point, check the uncles and transactions
-	header := block.Header()
-	if err := v.engine.VerifyUncles(v.bc, block); err != nil {
+	// Header validity is known at this point, run the cheap body checks first
+	if err := v.validateExtra(header); err != nil {
 		return err
 	}
-	if hash := types.CalcUncleHash(block.Uncles()); hash != header.UncleHash {
-		return fmt.Errorf("uncle root hash mismatch: have %x, want %x", hash, header.UncleHash)
+	if err := v.validateSlot(header); err != nil {
+		return err
 	}
-
+	if err := v.validateTimeGap(header); err != nil {
+		return err
+	}
+	if err := v.validateFutureTime(header); err != nil {
+		return err
+	}
+	if err := v.checkNonceReuse(header); err != nil {
+		return err
+	}
+	if err := v.validateCoinbase(header); err != nil {
+		return err
+	}
+	if err := v.validateSealer(header); err != nil {
+		return err
+	}
+	if err := v.validateZeroCoinbase(header); err != nil {
+		return err
+	}
+	if err := v.validateTxCount(block); err != nil {
+		return err
+	}
+	if err := validateUncleNumbers(block); err != nil {
+		return err
+	}
+	if err := validateUniqueUncles(block); err != nil {
+		return err
+	}
+	if v.config.IsShanghai(header.Number) {
+		if err := validateInitCodeSizes(block.Transactions()); err != nil {
+			return err
+		}
+	}
+	// Check the uncles and transactions concurrently. Verifying the uncles only
+	// reads the chain, so it's safe to run next to the other checks.
 	validateFuns := []func() error{
 		func() error {
-			if v.bc.HasBlockAndState(block.Hash(), block.NumberU64()) {
+			if !candidate && v.bc.HasBlockAndState(block.Hash(), block.NumberU64()) {
 				return ErrKnownBlock
 			}
 			return nil
 		},
 		func() error {
-			if hash := types.DeriveSha(block.Transactions(), trie.NewStackTrie(nil)); hash != header.TxHash {
-				return fmt.Errorf("transaction root hash mismatch: have %x, want %x", hash, header.TxHash)
-			}
-			return nil
+			return v.validateExtraSize(header)
+		},
+		func() error {
+			return v.validateTimestamp(header)
+		},
+		func() error {
+			return v.validateGasLimit(header)
+		},
+		func() error {
+			return v.validateDifficulty(header)
+		},
+		func() error {
+			return v.validateBlockSize(block)
 		},
 		func() error {
 			if !v.bc.HasBlockAndState(block.ParentHash(), block.NumberU64()-1) {
@@ -85,13 +398,29 @@ func (v *BlockValidator) ValidateBody(block *types.Block) error {
 			return nil
 		},
 	}
-	validateRes := make(chan error, len(validateFuns))
-	for _, f := range validateFuns {
-		tmpFunc := f
-		go func() {
-			validateRes <- tmpFunc()
-		}()
+	// Blocks sealed locally were built from trusted state, recomputing their uncles
+	// and transaction root would only repeat the work of the miner
+	if !v.isLocal(block) {
+		validateFuns = append(validateFuns,
+			func() error {
+				return v.engine.VerifyUncles(v.bc, block)
+			},
+			func() error {
+				if hash := types.CalcUncleHash(block.Uncles()); hash != header.UncleHash {
+					return fmt.Errorf("uncle root hash mismatch: have %x, want %x", hash, header.UncleHash)
+				}
+				return nil
+			},
+			func() error {
+				defer txRootTimer.UpdateSince(time.Now())
+				if hash := deriveSha(block.Transactions()); hash != header.TxHash {
+					return fmt.Errorf("transaction root hash mismatch: have %x, want %x", hash, header.TxHash)
+				}
+				return nil
+			},
+		)
 	}
+	validateRes := validationPool.run(validateFuns)
 	for i := 0; i < len(validateFuns); i++ {
 		r := <-validateRes
 		if r != nil {
@@ -101,77 +430,565 @@ func (v *BlockValidator) ValidateBody(block *types.Block) error {
 	return nil
 }
 
+// validateExtraSize checks that the header's extra-data doesn't exceed the size
+// allowed by the protocol. Engines keeping their seals in the extra-data define
+// its layout on their own and are exempt.
+func (v *BlockValidator) validateExtraSize(header *types.Header) error {
+	if v.config.Clique != nil || v.config.Parlia != nil || v.config.Dpos != nil {
+		return nil
+	}
+	if size := uint64(len(header.Extra)); size > params.MaximumExtraDataSize {
+		return fmt.Errorf("%w: %d > %d", ErrExtraDataTooLong, size, params.MaximumExtraDataSize)
+	}
+	return nil
+}
+
+// validateExtra checks that the header's extra-data doesn't start with any of
+// the prefixes the network reserved, e.g. to stop miners impersonating official
+// pool tags.
+func (v *BlockValidator) validateExtra(header *types.Header) error {
+	if prefix, ok := v.config.ForbiddenExtraPrefix(header.Extra); ok {
+		return fmt.Errorf("%w: extra %x starts with %x", ErrForbiddenExtraData, header.Extra, prefix)
+	}
+	return nil
+}
+
 // ValidateState validates the various changes that happen after a state
 // transition, such as amount of used gas, the receipt roots and the state root
 // itself. ValidateState returns a database batch if the validation was a success
 // otherwise nil and an error is returned.
 func (v *BlockValidator) ValidateState(block *types.Block, statedb *state.StateDB, receipts types.Receipts, usedGas uint64) error {
+	return v.ValidateStateWithContext(context.Background(), block, statedb, receipts, usedGas)
+}
+
+// ValidateStateWithContext is like ValidateState, but abandons the validation and
+// returns the context's error as soon as ctx is cancelled, e.g. on shutdown.
+func (v *BlockValidator) ValidateStateWithContext(ctx context.Context, block *types.Block, statedb *state.StateDB, receipts types.Receipts, usedGas uint64) error {
+	err := v.validateState(ctx, block, statedb, receipts, usedGas)
+	if err != nil && err != ctx.Err() {
+		failure := ValidationFailure{
+			BlockHash: block.Hash(),
+			Number:    block.NumberU64(),
+			Kind:      validationFailureKind(err),
+			Err:       err,
+		}
+		v.recordFailure(block, err)
+		v.recordInvalid(failure)
+		v.failureFeed.Send(failure)
+	}
+	return err
+}
+
+// ValidateCandidate runs the body and state validation of a block without importing
+// it, letting external block builders check a candidate against the rules of the
+// chain. The state must be the one resulting from executing the block on top of
+// its parent, which has to be known, but not necessarily canonical. Unlike during
+// import, a candidate isn't rejected for being known already, and its failures
+// are neither recorded nor announced.
+func (v *BlockValidator) ValidateCandidate(block *types.Block, statedb *state.StateDB, receipts types.Receipts, usedGas uint64) error {
+	if err := v.validateBody(block, true); err != nil {
+		return err
+	}
+	return v.validateState(context.Background(), block, statedb, receipts, usedGas)
+}
+
+func (v *BlockValidator) validateState(ctx context.Context, block *types.Block, statedb *state.StateDB, receipts types.Receipts, usedGas uint64) error {
 	header := block.Header()
+	if block.GasUsed() > block.GasLimit() {
+		return fmt.Errorf("%w: have %d, limit %d", ErrGasUsedExceedsLimit, block.GasUsed(), block.GasLimit())
+	}
 	if block.GasUsed() != usedGas {
-		return fmt.Errorf("invalid gas used (remote: %d local: %d)", block.GasUsed(), usedGas)
+		return &StateValidationError{Kind: ValidationGasUsed, Have: block.GasUsed(), Want: usedGas, BlockNumber: block.NumberU64()}
+	}
+	// Blocks without transactions can't use gas, unless the chain lets the engine
+	// charge for its own system calls
+	if len(block.Transactions()) == 0 && block.GasUsed() != 0 && !v.config.SystemTxGas {
+		return fmt.Errorf("invalid gas used for empty block: %d", block.GasUsed())
 	}
 	// Validate the received block's bloom with the one derived from the generated receipts.
 	// For valid blocks this should always validate to true.
 	validateFuns := []func() error{
 		func() error {
+			defer bloomTimer.UpdateSince(time.Now())
 			rbloom := types.CreateBloom(receipts)
 			if rbloom != header.Bloom {
-				return fmt.Errorf("invalid bloom (remote: %x  local: %x)", header.Bloom, rbloom)
+				return &StateValidationError{Kind: ValidationBloom, Have: header.Bloom, Want: rbloom, BlockNumber: block.NumberU64()}
 			}
 			return nil
 		},
 		func() error {
-			receiptSha := types.DeriveSha(receipts, trie.NewStackTrie(nil))
+			return v.validateReceiptKinds(header, receipts)
+		},
+		func() error {
+			return validateFeeSum(block.Transactions(), receipts)
+		},
+		func() error {
+			return validateReceiptGas(header, receipts, v.config.SystemTxGas)
+		},
+		func() error {
+			return v.validateLogDataSize(receipts)
+		},
+		func() error {
+			defer receiptsTimer.UpdateSince(time.Now())
+			receiptSha := deriveSha(receipts)
 			if receiptSha != header.ReceiptHash {
-				return fmt.Errorf("invalid receipt root hash (remote: %x local: %x)", header.ReceiptHash, receiptSha)
+				return &StateValidationError{Kind: ValidationReceiptRoot, Have: header.ReceiptHash, Want: receiptSha, BlockNumber: block.NumberU64()}
 			} else {
 				return nil
 			}
 		},
 		func() error {
+			defer stateRootTimer.UpdateSince(time.Now())
 			if root := statedb.IntermediateRoot(v.config.IsEIP158(header.Number)); header.Root != root {
-				transaction_str := ""
-				for _, oneTrx := range block.Transactions() {
-					txJson, err := oneTrx.MarshalJSON()
-					if err == nil {
-						transaction_str += string(txJson) + "\n"
-					} else {
-						transaction_str += "marshal json failed tx hash " + string(oneTrx.Hash().String()) + "\n"
-					}
-
+				if atomic.LoadInt32(&v.dumpOnRoot) == 1 {
+					log.Error("Invalid merkle root", "number", header.Number, "hash", block.Hash(), "coinbase", header.Coinbase, "have", header.Root, "want", root, "txs", dumpTransactions(block.Transactions()))
 				}
-				//err_str := fmt.Errorf("invalid merkle root block number%v blockVal:%v transaction %v", header.Number.String(), header.Coinbase.String(), transaction_str)
-				//log.Error("invalid merkle root block", "error", err_str)
-				//statedb.IterativeDump(true, true, true, json.NewEncoder(os.Stdout))
-				return fmt.Errorf("invalid merkle root (remote: %x local: %x)", header.Root, root)
+				return &StateValidationError{Kind: ValidationStateRoot, Have: header.Root, Want: root, BlockNumber: block.NumberU64()}
 			} else {
 				return nil
 			}
 		},
 	}
-	validateRes := make(chan error, len(validateFuns))
-	for _, f := range validateFuns {
-		tmpFunc := f
-		go func() {
-			validateRes <- tmpFunc()
-		}()
+	validateRes := validationPool.run(validateFuns)
+	// Abandon the checks if they don't finish in time, the buffered result channel
+	// lets the stragglers finish on their own
+	var deadline <-chan time.Time
+	if timeout := v.validationTimeout(); timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		deadline = timer.C
 	}
 	for i := 0; i < len(validateFuns); i++ {
-		r := <-validateRes
-		if r != nil {
-			return r
+		select {
+		case r := <-validateRes:
+			if r != nil {
+				return r
+			}
+		case <-deadline:
+			return fmt.Errorf("%w: block %d [%x] not validated within %v", ErrValidationTimeout, block.NumberU64(), block.Hash(), v.validationTimeout())
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// dumpTransactions encodes the transactions as JSON, one per line, for debugging.
+func dumpTransactions(txs types.Transactions) string {
+	var dump strings.Builder
+	for _, tx := range txs {
+		blob, err := tx.MarshalJSON()
+		if err != nil {
+			fmt.Fprintf(&dump, "marshal json failed tx hash %s: %v\n", tx.Hash(), err)
+			continue
+		}
+		dump.Write(blob)
+		dump.WriteByte('\n')
+	}
+	return dump.String()
+}
+
+// validateFeeSum sums the fees paid by each transaction and checks that the
+// total, which the block reward is derived from, fits in 256 bits.
+func validateFeeSum(txs types.Transactions, receipts types.Receipts) error {
+	if len(receipts) != len(txs) {
+		return fmt.Errorf("%w: have %d, want %d", ErrReceiptCount, len(receipts), len(txs))
+	}
+	var (
+		total = new(big.Int)
+		fee   = new(big.Int)
+	)
+	for i, receipt := range receipts {
+		fee.SetUint64(receipt.GasUsed)
+		fee.Mul(fee, effectiveGasPrice(txs[i]))
+		if total.Add(total, fee).BitLen() > 256 {
+			return fmt.Errorf("%w: tx %d [%x]", ErrFeeOverflow, i, txs[i].Hash())
+		}
+	}
+	return nil
+}
+
+// effectiveGasPrice returns the price per gas a transaction pays to the coinbase.
+// The London fork of this chain only changes the gas limit rules and its headers
+// carry no base fee, so before and after it alike that's the gas price charged by
+// the state transition.
+func effectiveGasPrice(tx *types.Transaction) *big.Int {
+	return tx.ImmutableGasPrice()
+}
+
+// validateReceiptGas checks that the receipts' cumulative gas counters never go
+// down and that the last one equals the header's gas used, cross-checking the gas
+// accounting independently of the receipt root. If systemGas is set, a block
+// without receipts may report the gas charged by the engine's system calls.
+func validateReceiptGas(header *types.Header, receipts types.Receipts, systemGas bool) error {
+	if len(receipts) == 0 && systemGas {
+		return nil
+	}
+	var last uint64
+	for i, receipt := range receipts {
+		if receipt.CumulativeGasUsed < last {
+			return fmt.Errorf("%w: receipt %d has %d, previous %d", ErrCumulativeGasDecreased, i, receipt.CumulativeGasUsed, last)
+		}
+		last = receipt.CumulativeGasUsed
+	}
+	if last != header.GasUsed {
+		return &StateValidationError{Kind: ValidationCumulativeGas, Have: header.GasUsed, Want: last, BlockNumber: header.Number.Uint64()}
+	}
+	return nil
+}
+
+// validateLogDataSize checks that the logs emitted by the block don't carry more
+// data than the chain configuration allows.
+func (v *BlockValidator) validateLogDataSize(receipts types.Receipts) error {
+	limit := v.config.MaxLogDataBytes
+	if limit == 0 {
+		return nil
+	}
+	var size uint64
+	for _, receipt := range receipts {
+		for _, l := range receipt.Logs {
+			size += uint64(len(l.Data))
+		}
+	}
+	if size > limit {
+		return fmt.Errorf("%w: %d bytes, limit %d", ErrLogDataTooLarge, size, limit)
+	}
+	return nil
+}
+
+// validateSlot checks that the header's timestamp falls on a slot boundary,
+// counted from the genesis block, within the configured tolerance. The check is
+// skipped if the chain doesn't use a slot schedule.
+func (v *BlockValidator) validateSlot(header *types.Header) error {
+	period := v.config.BlockSlotPeriod
+	if period == 0 {
+		return nil
+	}
+	genesis := v.bc.Genesis().Time()
+	if header.Time < genesis {
+		return fmt.Errorf("%w: timestamp %d before genesis %d", ErrMisalignedSlot, header.Time, genesis)
+	}
+	// Measure the distance to the closest slot boundary, either side
+	offset := (header.Time - genesis) % period
+	if offset > period-offset {
+		offset = period - offset
+	}
+	if offset > v.config.BlockSlotTolerance {
+		return fmt.Errorf("%w: timestamp %d off by %ds from %ds slots (tolerance %ds)", ErrMisalignedSlot, header.Time, offset, period, v.config.BlockSlotTolerance)
+	}
+	return nil
+}
+
+// validateTimestamp checks that the header's timestamp is strictly after its
+// parent's. Missing parents are reported by the ancestry check instead.
+func (v *BlockValidator) validateTimestamp(header *types.Header) error {
+	if header.Number.Sign() == 0 {
+		return nil
+	}
+	number := header.Number.Uint64() - 1
+	if !v.bc.HasBlock(header.ParentHash, number) {
+		return nil
+	}
+	parent := v.bc.GetHeader(header.ParentHash, number)
+	if parent == nil {
+		return nil
+	}
+	if header.Time <= parent.Time {
+		return fmt.Errorf("%w: %d <= parent %d", ErrNonMonotonicTimestamp, header.Time, parent.Time)
+	}
+	return nil
+}
+
+// validateGasLimit checks that the header's gas limit stays within the bounds
+// the parent's allows, mirroring the engines' header checks. Missing parents are
+// reported by the ancestry check instead.
+func (v *BlockValidator) validateGasLimit(header *types.Header) error {
+	if v.config.Clique != nil || header.Number.Sign() == 0 {
+		return nil
+	}
+	number := header.Number.Uint64() - 1
+	if !v.bc.HasBlock(header.ParentHash, number) {
+		return nil
+	}
+	parent := v.bc.GetHeader(header.ParentHash, number)
+	if parent == nil {
+		return nil
+	}
+	diff := parent.GasLimit - header.GasLimit
+	if header.GasLimit > parent.GasLimit {
+		diff = header.GasLimit - parent.GasLimit
+	}
+	limit := parent.GasLimit / params.GasLimitBoundDivisor
+	if diff >= limit || header.GasLimit < params.MinGasLimit {
+		return fmt.Errorf("%w: have %d, want %d += %d", ErrInvalidGasLimit, header.GasLimit, parent.GasLimit, limit)
+	}
+	return nil
+}
+
+// validateTxCount checks that the block doesn't carry more transactions than the
+// chain allows, ahead of the costly transaction root derivation.
+func (v *BlockValidator) validateTxCount(block *types.Block) error {
+	limit := v.config.MaxTxPerBlock
+	if limit == 0 {
+		return nil
+	}
+	if count := uint64(len(block.Transactions())); count > limit {
+		return fmt.Errorf("%w: %d > %d", ErrTooManyTransactions, count, limit)
+	}
+	return nil
+}
+
+// validateBlockSize checks that the block's RLP encoding doesn't exceed the size
+// the chain allows. The encoded size is cached in the block, so it's computed at
+// most once.
+func (v *BlockValidator) validateBlockSize(block *types.Block) error {
+	limit := v.config.MaxBlockBytes
+	if limit == 0 {
+		return nil
+	}
+	if size := uint64(block.Size()); size > limit {
+		return fmt.Errorf("%w: %d bytes > %d", ErrOversizedBlock, size, limit)
+	}
+	return nil
+}
+
+// validateFutureTime checks that the header's timestamp isn't further ahead of the
+// local clock than the chain allows. The check is off unless a drift is configured.
+func (v *BlockValidator) validateFutureTime(header *types.Header) error {
+	drift := v.config.AllowedFutureDrift
+	if drift == 0 {
+		return nil
+	}
+	if now := uint64(time.Now().Unix()); header.Time > now+drift {
+		return fmt.Errorf("%w: %ds ahead of local time, allowed %ds", consensus.ErrFutureBlock, header.Time-now, drift)
+	}
+	return nil
+}
+
+// validateDifficulty checks that the block's difficulty is the one the engine
+// computes from its parent. The difficulty of the proof-of-authority engines
+// depends on the sealer, which CalcDifficulty can't tell, so they're skipped.
+// Engines accepting all headers unverified and blocks whose parent isn't
+// available are skipped too.
+func (v *BlockValidator) validateDifficulty(header *types.Header) error {
+	if v.config.Clique != nil || v.config.Parlia != nil || v.config.Dpos != nil || header.Number.Sign() == 0 {
+		return nil
+	}
+	if faker, ok := v.engine.(consensus.Faker); ok && faker.FullFake() {
+		return nil
+	}
+	parent := v.bc.GetHeader(header.ParentHash, header.Number.Uint64()-1)
+	if parent == nil {
+		return nil
+	}
+	if want := v.engine.CalcDifficulty(v.bc, header.Time, parent); header.Difficulty.Cmp(want) != 0 {
+		return fmt.Errorf("%w: have %v, want %v", ErrInvalidDifficulty, header.Difficulty, want)
+	}
+	return nil
+}
+
+// validateTimeGap checks that the header's timestamp isn't further ahead of its
+// parent's than configured, which hints at a miner with a skewed clock. Unknown
+// parents are left to the ancestry checks.
+func (v *BlockValidator) validateTimeGap(header *types.Header) error {
+	limit := v.config.MaxBlockTimeGap
+	if limit == 0 || header.Number.Sign() == 0 {
+		return nil
+	}
+	parent := v.bc.GetHeader(header.ParentHash, header.Number.Uint64()-1)
+	if parent == nil || header.Time <= parent.Time {
+		return nil
+	}
+	if gap := header.Time - parent.Time; gap > limit {
+		return fmt.Errorf("%w: %ds after parent, limit %ds", ErrBlockTimeGap, gap, limit)
+	}
+	return nil
+}
+
+// validateUncleNumbers checks that every uncle's number is strictly below the
+// block's own and within the allowed uncle depth, i.e. from the parent's number
+// down to maxUncleDepth below the block. This is a cheap guard ahead of the
+// engine's full uncle verification, telling apart uncles too old and too young.
+func validateUncleNumbers(block *types.Block) error {
+	number := block.NumberU64()
+	for _, uncle := range block.Uncles() {
+		if uncle.Number == nil || !uncle.Number.IsUint64() {
+			return fmt.Errorf("uncle %x has invalid number %v", uncle.Hash(), uncle.Number)
+		}
+		n := uncle.Number.Uint64()
+		if n >= number {
+			return fmt.Errorf("%w: uncle %x number %d, block %d", ErrUncleTooYoung, uncle.Hash(), n, number)
+		}
+		if n+maxUncleDepth < number {
+			return fmt.Errorf("%w: uncle %x number %d, block %d (max depth %d)", ErrUncleTooOld, uncle.Hash(), n, number, maxUncleDepth)
 		}
 	}
 	return nil
 }
 
+// validateUniqueUncles checks that the block doesn't include the same uncle more
+// than once.
+func validateUniqueUncles(block *types.Block) error {
+	uncles := block.Uncles()
+	if len(uncles) < 2 {
+		return nil
+	}
+	seen := make(map[common.Hash]struct{}, len(uncles))
+	for _, uncle := range uncles {
+		hash := uncle.Hash()
+		if _, ok := seen[hash]; ok {
+			return fmt.Errorf("duplicate uncle %x", hash)
+		}
+		seen[hash] = struct{}{}
+	}
+	return nil
+}
+
+// validateInitCodeSizes checks that no contract creation transaction carries
+// init code above the limit enforced since Shanghai.
+func validateInitCodeSizes(txs types.Transactions) error {
+	for i, tx := range txs {
+		if tx.To() != nil {
+			continue
+		}
+		if size := len(tx.Data()); size > params.MaxInitCodeSize {
+			return fmt.Errorf("%w: tx %d [%x] init code size %d, limit %d", ErrMaxInitCodeSizeExceeded, i, tx.Hash(), size, params.MaxInitCodeSize)
+		}
+	}
+	return nil
+}
+
+// validateCoinbase checks that the block reward goes to the block's sealer, as
+// recovered by the consensus engine. This only applies to permissioned networks
+// requiring it; on open proof-of-work chains the coinbase is freely chosen.
+func (v *BlockValidator) validateCoinbase(header *types.Header) error {
+	if !v.config.CoinbaseIsSealer {
+		return nil
+	}
+	sealer, err := v.engine.Author(header)
+	if err != nil {
+		return err
+	}
+	if header.Coinbase != sealer {
+		return fmt.Errorf("%w: coinbase %x, sealer %x", ErrCoinbaseNotSealer, header.Coinbase, sealer)
+	}
+	return nil
+}
+
+// validateZeroCoinbase checks that a proof-of-work block doesn't burn its reward
+// by paying the zero address, once the chain forbids it. Only the block's own
+// coinbase is checked, its uncles are accepted as they are. Proof-of-authority
+// engines legitimately leave the coinbase empty, so they're skipped.
+func (v *BlockValidator) validateZeroCoinbase(header *types.Header) error {
+	if v.config.Clique != nil || v.config.Parlia != nil || v.config.Dpos != nil {
+		return nil
+	}
+	if header.Coinbase == (common.Address{}) && v.config.IsNonZeroCoinbase(header.Number) {
+		return fmt.Errorf("%w: block %d", ErrZeroCoinbase, header.Number)
+	}
+	return nil
+}
+
+// validateSealer checks that the header's coinbase is one of the sealers the chain
+// authorized, if it restricts them at all.
+func (v *BlockValidator) validateSealer(header *types.Header) error {
+	if len(v.config.AuthorizedSealers) == 0 {
+		return nil
+	}
+	for _, sealer := range v.config.AuthorizedSealers {
+		if header.Coinbase == sealer {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %x", ErrUnauthorizedSealer, header.Coinbase)
+}
+
+// recentNonce is the block a nonce was last seen on by the nonce reuse diagnostic.
+type recentNonce struct {
+	number uint64
+	hash   common.Hash
+}
+
+// checkNonceReuse flags the header if its nonce was seen on a different block
+// within the configured window, and records it for future checks.
+func (v *BlockValidator) checkNonceReuse(header *types.Header) error {
+	v.nonceLock.Lock()
+	defer v.nonceLock.Unlock()
+
+	if v.recentNonces == nil {
+		return nil
+	}
+	number, hash := header.Number.Uint64(), header.Hash()
+	if prev, ok := v.recentNonces.Get(header.Nonce); ok {
+		seen := prev.(recentNonce)
+		distance := number - seen.number
+		if seen.number > number {
+			distance = seen.number - number
+		}
+		if seen.hash != hash && distance <= v.nonceWindow {
+			if v.nonceReject {
+				return fmt.Errorf("%w: nonce %d of block %d already seen on block %d [%x]", ErrNonceReuse, header.Nonce.Uint64(), number, seen.number, seen.hash)
+			}
+			log.Warn("Block reuses a recently seen nonce", "number", number, "hash", hash, "nonce", header.Nonce.Uint64(), "seen", seen.number, "seenhash", seen.hash)
+		}
+	}
+	v.recentNonces.Add(header.Nonce, recentNonce{number: number, hash: hash})
+	return nil
+}
+
+// validateReceiptKinds checks that the receipts use the representation mandated
+// by the block's fork: intermediate state roots before Byzantium and status codes
+// afterwards.
+func (v *BlockValidator) validateReceiptKinds(header *types.Header, receipts types.Receipts) error {
+	byzantium := v.config.IsByzantium(header.Number)
+	for i, receipt := range receipts {
+		if byzantium && len(receipt.PostState) != 0 {
+			return fmt.Errorf("invalid receipt %d: have state root, want status code (post-Byzantium)", i)
+		}
+		if !byzantium && len(receipt.PostState) != common.HashLength {
+			return fmt.Errorf("invalid receipt %d: have status code, want state root (pre-Byzantium)", i)
+		}
+	}
+	return nil
+}
+
+// GasLimitTarget is the ratio the parent's gas used is scaled by when computing
+// the next gas limit. The limit grows while blocks use more than the inverse of
+// the ratio, so the default 3/2 targets a 2/3 utilization.
+type GasLimitTarget struct {
+	Numerator   uint64
+	Denominator uint64
+}
+
+// DefaultGasLimitTarget is the gas limit target of the protocol.
+var DefaultGasLimitTarget = GasLimitTarget{
+	Numerator:   params.GasTargetNumerator,
+	Denominator: params.GasTargetDenominator,
+}
+
+// scale returns gas scaled by the target ratio, rounding down. Zero ratios fall
+// back to the default target.
+func (t GasLimitTarget) scale(gas uint64) uint64 {
+	if t.Numerator == 0 || t.Denominator == 0 {
+		t = DefaultGasLimitTarget
+	}
+	// Split the division to avoid overflowing on the multiplication
+	return gas/t.Denominator*t.Numerator + gas%t.Denominator*t.Numerator/t.Denominator
+}
+
 // CalcGasLimit computes the gas limit of the next block after parent. It aims
 // to keep the baseline gas above the provided floor, and increase it towards the
 // ceil if the blocks are full. If the ceil is exceeded, it will always decrease
 // the gas allowance.
 func CalcGasLimit(parent *types.Block, gasFloor, gasCeil uint64) uint64 {
-	// contrib = (parentGasUsed * 3 / 2) / 256
-	contrib := (parent.GasUsed() + parent.GasUsed()/2) / params.GasLimitBoundDivisor
+	return CalcGasLimitWithTarget(parent, gasFloor, gasCeil, DefaultGasLimitTarget)
+}
+
+// CalcGasLimitWithTarget is like CalcGasLimit, but aims for the utilization set
+// by the given target instead of the protocol default.
+func CalcGasLimitWithTarget(parent *types.Block, gasFloor, gasCeil uint64, target GasLimitTarget) uint64 {
+	// contrib = (parentGasUsed * numerator / denominator) / 256
+	contrib := target.scale(parent.GasUsed()) / params.GasLimitBoundDivisor
 
 	// decay = parentGasLimit / 256 -1
 	decay := parent.GasLimit()/params.GasLimitBoundDivisor - 1
@@ -181,7 +998,8 @@ func CalcGasLimit(parent *types.Block, gasFloor, gasCeil uint64) uint64 {
 		gasUsed value.  if parentGasUsed > parentGasLimit * (2/3) then we
 		increase it, otherwise lower it (or leave it unchanged if it's right
 		at that usage) the amount increased/decreased depends on how far away
-		from parentGasLimit * (2/3) parentGasUsed is.
+		from parentGasLimit * (2/3) parentGasUsed is. The 2/3 is the inverse
+		of the target ratio.
 	*/
 	limit := parent.GasLimit() - decay + contrib
 	if limit < params.MinGasLimit {
@@ -201,3 +1019,83 @@ func CalcGasLimit(parent *types.Block, gasFloor, gasCeil uint64) uint64 {
 	}
 	return limit
 }
+
+// CalcGasLimit1559 computes the gas limit of the next block after parent once
+// London is active. Unlike CalcGasLimit it ignores how full the parent was and
+// moves the limit towards the ceil by the largest step the header rules allow.
+//
+// The fork block keeps the parent's limit as its base: headers are still bound
+// to the legacy gas limit delta, which leaves no room for the one-off elasticity
+// doubling of EIP-1559.
+func CalcGasLimit1559(parent *types.Block, gasCeil uint64) uint64 {
+	if gasCeil < params.MinGasLimit {
+		gasCeil = params.MinGasLimit
+	}
+	var (
+		limit = parent.GasLimit()
+		delta = limit/params.GasLimitBoundDivisor - 1
+	)
+	switch {
+	case limit < gasCeil:
+		limit += delta
+		if limit > gasCeil {
+			limit = gasCeil
+		}
+	case limit > gasCeil:
+		limit -= delta
+		if limit < gasCeil {
+			limit = gasCeil
+		}
+	}
+	return limit
+}
+
+// validationPool runs the checks of block and state validation on a fixed set of
+// workers, capping their concurrency at GOMAXPROCS and sparing a goroutine per
+// check on every imported block. It is a package global on purpose: all the
+// validators of the process, of every chain, share the same workers, which live
+// for the lifetime of the process and are never stopped.
+var validationPool = newWorkerPool(runtime.GOMAXPROCS(0))
+
+// workerPool is a bounded set of long lived goroutines executing tasks.
+type workerPool struct {
+	tasks chan func()
+}
+
+// newWorkerPool starts a worker pool with the given number of workers.
+func newWorkerPool(size int) *workerPool {
+	if size < 1 {
+		size = 1
+	}
+	pool := &workerPool{tasks: make(chan func())}
+	for i := 0; i < size; i++ {
+		go pool.loop()
+	}
+	return pool
+}
+
+// loop executes the scheduled tasks for the lifetime of the process.
+func (p *workerPool) loop() {
+	for task := range p.tasks {
+		task()
+	}
+}
+
+// run schedules all the funcs on the pool and returns a channel delivering their
+// results in completion order. The funcs are handed to the workers in the
+// background, so callers waiting on the results can still honour deadlines and
+// cancellation while all workers are busy.
+//
+// Every func is run, even if the caller stops collecting the results after the
+// first error. The channel is buffered for every result, so the funcs never block
+// on an abandoned channel.
+func (p *workerPool) run(funcs []func() error) <-chan error {
+	results := make(chan error, len(funcs))
+	go func() {
+		for _, f := range funcs {
+			f := f
+			p.tasks <- func() { results <- f() }
+		}
+	}()
+	return results
+}
@@ -17,15 +17,26 @@
 package core
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
 	"runtime"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"PureChain/common"
+	"PureChain/common/hexutil"
+	"PureChain/consensus"
 	"PureChain/consensus/ethash"
 	"PureChain/core/rawdb"
+	"PureChain/core/state"
 	"PureChain/core/types"
 	"PureChain/core/vm"
 	"PureChain/params"
+	"PureChain/trie"
 )
 
 // Tests that simple header verification works, for both good and bad blocks.
@@ -76,6 +87,27 @@ func TestHeaderVerification(t *testing.T) {
 	}
 }
 
+// Tests that block bodies with oversized extra-data are rejected.
+func TestBodyExtraDataValidation(t *testing.T) {
+	var (
+		testdb    = rawdb.NewMemoryDatabase()
+		gspec     = &Genesis{Config: params.TestChainConfig}
+		genesis   = gspec.MustCommit(testdb)
+		blocks, _ = GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), testdb, 1, nil)
+	)
+	chain, _ := NewBlockChain(testdb, nil, params.TestChainConfig, ethash.NewFaker(), vm.Config{}, nil, nil)
+	defer chain.Stop()
+
+	if err := chain.Validator().ValidateBody(blocks[0]); err != nil {
+		t.Fatalf("valid block rejected: %v", err)
+	}
+	header := blocks[0].Header()
+	header.Extra = make([]byte, params.MaximumExtraDataSize+1)
+	if err := chain.Validator().ValidateBody(blocks[0].WithSeal(header)); !errors.Is(err, ErrExtraDataTooLong) {
+		t.Errorf("oversized extra-data: have %v, want %v", err, ErrExtraDataTooLong)
+	}
+}
+
 // Tests that concurrent header verification works, for both good and bad blocks.
 func TestHeaderConcurrentVerification2(t *testing.T)  { testHeaderConcurrentVerification(t, 2) }
 func TestHeaderConcurrentVerification8(t *testing.T)  { testHeaderConcurrentVerification(t, 8) }
@@ -197,3 +229,1048 @@ func testHeaderConcurrentAbortion(t *testing.T, threads int) {
 		t.Errorf("verification count too large: have %d, want below %d", verified, 2*threads)
 	}
 }
+
+// Tests that blocks claiming to use more gas than their limit are rejected, even
+// if the execution used the same amount.
+func TestStateGasUsedAboveLimit(t *testing.T) {
+	validator := NewBlockValidator(params.TestChainConfig, nil, nil)
+
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1), GasLimit: 21000, GasUsed: 42000})
+	if err := validator.ValidateState(block, nil, nil, 42000); !errors.Is(err, ErrGasUsedExceedsLimit) {
+		t.Errorf("error mismatch: have %v, want %v", err, ErrGasUsedExceedsLimit)
+	}
+}
+
+// Tests that state validation failures are announced on the validator's feed.
+func TestValidationFailureFeed(t *testing.T) {
+	validator := NewBlockValidator(params.TestChainConfig, nil, nil)
+
+	failures := make(chan ValidationFailure, 1)
+	sub := validator.SubscribeValidationFailures(failures)
+	defer sub.Unsubscribe()
+
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1), GasLimit: 21000, GasUsed: 21000})
+	if err := validator.ValidateState(block, nil, nil, 42000); err == nil {
+		t.Fatal("mismatching gas used accepted")
+	}
+	select {
+	case failure := <-failures:
+		if failure.BlockHash != block.Hash() || failure.Number != 1 {
+			t.Errorf("block mismatch: have %d [%x], want 1 [%x]", failure.Number, failure.BlockHash, block.Hash())
+		}
+		if failure.Kind != ValidationGasUsed.String() {
+			t.Errorf("kind mismatch: have %s, want %s", failure.Kind, ValidationGasUsed)
+		}
+	default:
+		t.Fatal("no validation failure posted")
+	}
+}
+
+// Tests that the most recent blocks rejected by state validation are retained,
+// newest first, up to the limit.
+func TestLastInvalidBlocks(t *testing.T) {
+	validator := NewBlockValidator(params.TestChainConfig, nil, nil)
+	if invalid := validator.LastInvalidBlocks(); len(invalid) != 0 {
+		t.Fatalf("fresh validator retained %d invalid blocks", len(invalid))
+	}
+	for i := 1; i <= maxInvalidBlocks+2; i++ {
+		block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(int64(i)), GasLimit: 21000, GasUsed: 21000})
+		if err := validator.ValidateState(block, nil, nil, 42000); err == nil {
+			t.Fatal("mismatching gas used accepted")
+		}
+	}
+	invalid := validator.LastInvalidBlocks()
+	if len(invalid) != maxInvalidBlocks {
+		t.Fatalf("invalid block count mismatch: have %d, want %d", len(invalid), maxInvalidBlocks)
+	}
+	for i, info := range invalid {
+		if want := uint64(maxInvalidBlocks + 2 - i); info.Number != want {
+			t.Errorf("entry %d: number mismatch: have %d, want %d", i, info.Number, want)
+		}
+		if info.Kind != ValidationGasUsed.String() {
+			t.Errorf("entry %d: details mismatch: have %+v", i, info)
+		}
+	}
+}
+
+// Tests that the transaction dump logged on state root mismatches holds one JSON
+// encoded transaction per line.
+func TestDumpTransactions(t *testing.T) {
+	txs := types.Transactions{
+		types.NewTransaction(0, common.Address{0x01}, big.NewInt(1), params.TxGas, big.NewInt(1), nil),
+		types.NewTransaction(1, common.Address{0x02}, big.NewInt(2), params.TxGas, big.NewInt(1), nil),
+	}
+	lines := strings.Split(strings.TrimSuffix(dumpTransactions(txs), "\n"), "\n")
+	if len(lines) != len(txs) {
+		t.Fatalf("line count mismatch: have %d, want %d", len(lines), len(txs))
+	}
+	for i, line := range lines {
+		var tx types.Transaction
+		if err := json.Unmarshal([]byte(line), &tx); err != nil {
+			t.Fatalf("line %d: invalid transaction json: %v", i, err)
+		}
+		if tx.Hash() != txs[i].Hash() {
+			t.Errorf("line %d: hash mismatch: have %x, want %x", i, tx.Hash(), txs[i].Hash())
+		}
+	}
+}
+
+// Tests that receipts with decreasing cumulative gas, or whose last cumulative gas
+// doesn't match the header, are rejected with typed errors.
+func TestValidateReceiptGas(t *testing.T) {
+	receipts := func(gas ...uint64) types.Receipts {
+		list := make(types.Receipts, len(gas))
+		for i, g := range gas {
+			list[i] = &types.Receipt{CumulativeGasUsed: g}
+		}
+		return list
+	}
+	header := &types.Header{Number: big.NewInt(1), GasUsed: 63000}
+
+	if err := validateReceiptGas(header, receipts(21000, 42000, 63000), false); err != nil {
+		t.Errorf("valid receipts rejected: %v", err)
+	}
+	if err := validateReceiptGas(header, receipts(21000, 63000, 42000), false); !errors.Is(err, ErrCumulativeGasDecreased) {
+		t.Errorf("error mismatch: have %v, want %v", err, ErrCumulativeGasDecreased)
+	}
+	var stateErr *StateValidationError
+	if err := validateReceiptGas(header, receipts(21000, 42000), false); !errors.As(err, &stateErr) || stateErr.Kind != ValidationCumulativeGas {
+		t.Errorf("error mismatch: have %v, want %v mismatch", err, ValidationCumulativeGas)
+	}
+	// Gas used by an empty block is only accepted if the engine charges system calls
+	if err := validateReceiptGas(header, nil, false); !errors.As(err, &stateErr) || stateErr.Kind != ValidationCumulativeGas {
+		t.Errorf("error mismatch: have %v, want %v mismatch", err, ValidationCumulativeGas)
+	}
+	if err := validateReceiptGas(header, nil, true); err != nil {
+		t.Errorf("empty block with system gas rejected: %v", err)
+	}
+}
+
+// Tests that blocks encoding to more bytes than the configured limit are rejected.
+func TestValidateBlockSize(t *testing.T) {
+	config := *params.TestChainConfig
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1), Extra: make([]byte, 32)})
+	size := uint64(block.Size())
+
+	for _, tt := range []struct {
+		limit uint64
+		err   error
+	}{
+		{0, nil},
+		{size, nil},
+		{size - 1, ErrOversizedBlock},
+	} {
+		config.MaxBlockBytes = tt.limit
+		validator := NewBlockValidator(&config, nil, nil)
+		if err := validator.validateBlockSize(block); !errors.Is(err, tt.err) {
+			t.Errorf("limit %d: error mismatch: have %v, want %v", tt.limit, err, tt.err)
+		}
+	}
+}
+
+// Tests that reorgs rewriting more blocks than configured are rejected.
+func TestValidateReorg(t *testing.T) {
+	config := *params.TestChainConfig
+	for _, tt := range []struct {
+		limit    uint64
+		ancestor uint64
+		head     uint64
+		err      error
+	}{
+		{0, 100, 1000, nil},
+		{10, 100, 110, nil},
+		{10, 100, 111, ErrReorgTooDeep},
+		{10, 100, 100, nil},
+	} {
+		config.MaxReorgDepth = tt.limit
+		validator := NewBlockValidator(&config, nil, nil)
+		if err := validator.ValidateReorg(tt.ancestor, tt.head); !errors.Is(err, tt.err) {
+			t.Errorf("limit %d, reorg #%d->#%d: error mismatch: have %v, want %v", tt.limit, tt.ancestor, tt.head, err, tt.err)
+		}
+	}
+}
+
+// Tests that the chain refuses a reorg deeper than configured, keeping the old
+// canonical chain untouched.
+func TestReorgTooDeep(t *testing.T) {
+	config := *params.TestChainConfig
+	config.MaxReorgDepth = 3
+
+	var (
+		testdb  = rawdb.NewMemoryDatabase()
+		gspec   = &Genesis{Config: &config}
+		genesis = gspec.MustCommit(testdb)
+	)
+	canon, _ := GenerateChain(&config, genesis, ethash.NewFaker(), testdb, 5, nil)
+	fork, _ := GenerateChain(&config, genesis, ethash.NewFaker(), testdb, 8, func(i int, b *BlockGen) {
+		b.SetCoinbase(common.Address{0x01})
+	})
+	chain, _ := NewBlockChain(testdb, nil, &config, ethash.NewFaker(), vm.Config{}, nil, nil)
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(canon); err != nil {
+		t.Fatalf("failed to insert canonical chain: %v", err)
+	}
+	if _, err := chain.InsertChain(fork); !errors.Is(err, ErrReorgTooDeep) {
+		t.Fatalf("error mismatch: have %v, want %v", err, ErrReorgTooDeep)
+	}
+	if head := chain.CurrentBlock(); head.Hash() != canon[len(canon)-1].Hash() {
+		t.Errorf("head mismatch: have #%d [%x], want #%d [%x]", head.NumberU64(), head.Hash(), canon[len(canon)-1].NumberU64(), canon[len(canon)-1].Hash())
+	}
+	for _, block := range canon {
+		if hash := rawdb.ReadCanonicalHash(testdb, block.NumberU64()); hash != block.Hash() {
+			t.Errorf("canonical hash #%d mismatch: have %x, want %x", block.NumberU64(), hash, block.Hash())
+		}
+	}
+}
+
+// Tests that uncles outside the allowed window are rejected with typed errors.
+func TestValidateUncleNumbers(t *testing.T) {
+	for _, tt := range []struct {
+		uncle uint64
+		err   error
+	}{
+		{9, nil},
+		{4, nil},
+		{10, ErrUncleTooYoung},
+		{11, ErrUncleTooYoung},
+		{3, ErrUncleTooOld},
+	} {
+		block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(10)}).WithBody(nil, []*types.Header{{Number: new(big.Int).SetUint64(tt.uncle)}})
+		if err := validateUncleNumbers(block); !errors.Is(err, tt.err) {
+			t.Errorf("uncle %d: error mismatch: have %v, want %v", tt.uncle, err, tt.err)
+		}
+	}
+}
+
+// Tests that candidate blocks are fully validated even if they're known already.
+func TestValidateCandidate(t *testing.T) {
+	var (
+		testdb  = rawdb.NewMemoryDatabase()
+		gspec   = &Genesis{Config: params.TestChainConfig}
+		genesis = gspec.MustCommit(testdb)
+	)
+	blocks, _ := GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), testdb, 2, nil)
+	chain, _ := NewBlockChain(testdb, nil, params.TestChainConfig, ethash.NewFaker(), vm.Config{}, nil, nil)
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	block := blocks[1]
+	if err := chain.Validator().ValidateBody(block); err != ErrKnownBlock {
+		t.Fatalf("error mismatch: have %v, want %v", err, ErrKnownBlock)
+	}
+	statedb, err := state.New(blocks[0].Root(), chain.stateCache, nil)
+	if err != nil {
+		t.Fatalf("failed to open parent state: %v", err)
+	}
+	receipts, _, usedGas, err := chain.processor.Process(block, statedb, vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to process candidate: %v", err)
+	}
+	validator := chain.Validator().(*BlockValidator)
+	if err := validator.ValidateCandidate(block, statedb, receipts, usedGas); err != nil {
+		t.Errorf("valid candidate rejected: %v", err)
+	}
+	if err := validator.ValidateCandidate(block, statedb, receipts, usedGas+1); err == nil {
+		t.Errorf("candidate with mismatching gas used accepted")
+	}
+}
+
+// Tests that blocks whose difficulty differs from the one computed from their
+// parent are rejected, unless the engine accepts all headers unverified.
+func TestValidateDifficulty(t *testing.T) {
+	var (
+		testdb  = rawdb.NewMemoryDatabase()
+		gspec   = &Genesis{Config: params.TestChainConfig}
+		genesis = gspec.MustCommit(testdb)
+	)
+	blocks, _ := GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), testdb, 2, nil)
+	chain, _ := NewBlockChain(testdb, nil, params.TestChainConfig, ethash.NewFaker(), vm.Config{}, nil, nil)
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks[:1]); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	validator := chain.Validator().(*BlockValidator)
+	if err := validator.ValidateBody(blocks[1]); err != nil {
+		t.Fatalf("valid block rejected: %v", err)
+	}
+	header := blocks[1].Header()
+	header.Difficulty = new(big.Int).Add(header.Difficulty, big.NewInt(1))
+	block := types.NewBlockWithHeader(header).WithBody(blocks[1].Transactions(), blocks[1].Uncles())
+
+	if err := validator.ValidateBody(block); !errors.Is(err, ErrInvalidDifficulty) {
+		t.Errorf("error mismatch: have %v, want %v", err, ErrInvalidDifficulty)
+	}
+	validator = NewBlockValidator(params.TestChainConfig, chain, ethash.NewFullFaker())
+	if err := validator.ValidateBody(block); err != nil {
+		t.Errorf("block rejected by full fake engine: %v", err)
+	}
+}
+
+// Tests that only the locally sealed block itself skips the transaction root
+// recomputation, never another block claiming the same header.
+func TestValidateLocalBody(t *testing.T) {
+	var (
+		testdb  = rawdb.NewMemoryDatabase()
+		gspec   = &Genesis{Config: params.TestChainConfig}
+		genesis = gspec.MustCommit(testdb)
+	)
+	blocks, _ := GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), testdb, 2, nil)
+	chain, _ := NewBlockChain(testdb, nil, params.TestChainConfig, ethash.NewFaker(), vm.Config{}, nil, nil)
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks[:1]); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	// Attach a body not matching the header's transaction root
+	tx := types.NewTransaction(0, common.Address{0x01}, big.NewInt(1), params.TxGas, big.NewInt(1), nil)
+	local := blocks[1].WithBody(types.Transactions{tx}, nil)
+	remote := blocks[1].WithBody(types.Transactions{tx}, nil)
+
+	validator := chain.Validator().(*BlockValidator)
+	untrust := validator.trustLocal(local)
+	if err := validator.ValidateBody(local); err != nil {
+		t.Errorf("local block failed validation: %v", err)
+	}
+	if err := validator.ValidateBody(remote); err == nil {
+		t.Errorf("remote block with the same header passed validation")
+	}
+	untrust()
+	if err := validator.ValidateBody(local); err == nil {
+		t.Errorf("untrusted local block passed validation")
+	}
+}
+
+// Tests that proof-of-work blocks paying the zero coinbase are only rejected from
+// the activation block on, and that uncles are never checked.
+func TestValidateZeroCoinbase(t *testing.T) {
+	config := *params.TestChainConfig
+	config.NonZeroCoinbaseBlock = big.NewInt(3)
+
+	var (
+		testdb  = rawdb.NewMemoryDatabase()
+		gspec   = &Genesis{Config: &config}
+		genesis = gspec.MustCommit(testdb)
+	)
+	// Blocks before the activation pay the zero coinbase, the first one after it
+	// includes an uncle still paying it
+	blocks, _ := GenerateChain(&config, genesis, ethash.NewFaker(), testdb, 4, func(i int, block *BlockGen) {
+		if i < 2 {
+			return
+		}
+		block.SetCoinbase(common.Address{0x01})
+		if i == 2 {
+			uncle := block.PrevBlock(i - 1).Header()
+			uncle.Extra = []byte("uncle")
+			block.AddUncle(uncle)
+		}
+	})
+	chain, _ := NewBlockChain(testdb, nil, &config, ethash.NewFaker(), vm.Config{}, nil, nil)
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks[:3]); err != nil {
+		t.Fatalf("failed to insert blocks before activation and zero coinbase uncle: %v", err)
+	}
+	header := blocks[3].Header()
+	header.Coinbase = common.Address{}
+	block := types.NewBlockWithHeader(header).WithBody(blocks[3].Transactions(), blocks[3].Uncles())
+	if err := chain.Validator().ValidateBody(block); !errors.Is(err, ErrZeroCoinbase) {
+		t.Errorf("error mismatch: have %v, want %v", err, ErrZeroCoinbase)
+	}
+	// Proof-of-authority chains leave the coinbase empty on purpose
+	config.Clique = &params.CliqueConfig{Period: 1, Epoch: 30000}
+	if err := NewBlockValidator(&config, nil, nil).validateZeroCoinbase(header); err != nil {
+		t.Errorf("zero coinbase rejected on clique: %v", err)
+	}
+}
+
+// Tests that blocks from unauthorized coinbases are rejected only if the chain
+// restricts its sealers.
+func TestValidateSealer(t *testing.T) {
+	config := *params.TestChainConfig
+	header := &types.Header{Number: big.NewInt(1), Coinbase: common.Address{0x01}}
+
+	if err := NewBlockValidator(&config, nil, nil).validateSealer(header); err != nil {
+		t.Errorf("unrestricted chain rejected sealer: %v", err)
+	}
+	config.AuthorizedSealers = []common.Address{{0x02}, {0x01}}
+	if err := NewBlockValidator(&config, nil, nil).validateSealer(header); err != nil {
+		t.Errorf("authorized sealer rejected: %v", err)
+	}
+	config.AuthorizedSealers = []common.Address{{0x02}}
+	if err := NewBlockValidator(&config, nil, nil).validateSealer(header); !errors.Is(err, ErrUnauthorizedSealer) {
+		t.Errorf("error mismatch: have %v, want %v", err, ErrUnauthorizedSealer)
+	}
+}
+
+// Tests that reused block nonces are only flagged within the configured window,
+// and only rejected if requested.
+func TestNonceReuseCheck(t *testing.T) {
+	validator := NewBlockValidator(params.TestChainConfig, nil, nil)
+	header := func(number int64) *types.Header {
+		return &types.Header{Number: big.NewInt(number), Nonce: types.EncodeNonce(42)}
+	}
+	// The check is disabled by default
+	for _, number := range []int64{1, 2} {
+		if err := validator.checkNonceReuse(header(number)); err != nil {
+			t.Errorf("block %d: reuse flagged while disabled: %v", number, err)
+		}
+	}
+	// Reuses are only logged unless rejection is requested
+	validator.SetNonceReuseCheck(4, false)
+	for _, number := range []int64{1, 2} {
+		if err := validator.checkNonceReuse(header(number)); err != nil {
+			t.Errorf("block %d: reuse rejected while logging: %v", number, err)
+		}
+	}
+	validator.SetNonceReuseCheck(4, true)
+	for i, tt := range []struct {
+		number int64
+		err    error
+	}{
+		{10, nil},
+		{10, nil},           // Same block seen again
+		{12, ErrNonceReuse}, // Different block within the window
+		{20, nil},           // Different block beyond the window
+	} {
+		if err := validator.checkNonceReuse(header(tt.number)); !errors.Is(err, tt.err) {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+	validator.SetNonceReuseCheck(0, true)
+	if err := validator.checkNonceReuse(header(21)); err != nil {
+		t.Errorf("reuse flagged after disabling: %v", err)
+	}
+}
+
+// Tests that state validation gives up once the deadline passes or the context is
+// cancelled, even if the checks are stuck waiting for a worker.
+func TestValidationTimeout(t *testing.T) {
+	validator := NewBlockValidator(params.TestChainConfig, nil, nil)
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1), GasLimit: 21000})
+
+	// Keep all the validation workers busy so no check can finish
+	release := make(chan struct{})
+	defer close(release)
+busy:
+	for {
+		select {
+		case validationPool.tasks <- func() { <-release }:
+		case <-time.After(50 * time.Millisecond):
+			break busy
+		}
+	}
+	validator.SetValidationTimeout(50 * time.Millisecond)
+	if err := validator.ValidateState(block, statedb, nil, 0); !errors.Is(err, ErrValidationTimeout) {
+		t.Errorf("error mismatch: have %v, want %v", err, ErrValidationTimeout)
+	}
+	// Without a deadline the validation still honours cancellation
+	validator.SetValidationTimeout(0)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := validator.ValidateStateWithContext(ctx, block, statedb, nil, 0); err != context.DeadlineExceeded {
+		t.Errorf("error mismatch: have %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+// Tests that blocks too far ahead of the local clock are rejected if a drift is
+// configured, and that such blocks aren't recorded as invalid.
+func TestValidateFutureTime(t *testing.T) {
+	config := *params.TestChainConfig
+	validator := NewBlockValidator(&config, nil, nil)
+
+	now := uint64(time.Now().Unix())
+	for i, tt := range []struct {
+		drift uint64
+		time  uint64
+		err   error
+	}{
+		{0, now, nil},
+		{0, now + 3600, nil},
+		{15, now + 10, nil},
+		{15, now + 30, consensus.ErrFutureBlock},
+		{60, now + 30, nil},
+		{60, now + 120, consensus.ErrFutureBlock},
+	} {
+		config.AllowedFutureDrift = tt.drift
+		header := &types.Header{Number: big.NewInt(1), Time: tt.time}
+		if err := validator.validateFutureTime(header); !errors.Is(err, tt.err) {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+	config.AllowedFutureDrift = 15
+	header := &types.Header{Number: big.NewInt(1), Time: now + 30}
+	validator.recordFailure(types.NewBlockWithHeader(header), validator.validateFutureTime(header))
+	if err := validator.LastValidationError(); err != nil {
+		t.Errorf("future block recorded as failure: %v", err)
+	}
+}
+
+// Tests that the fee sum check rejects receipts not matching the transactions
+// and fee totals overflowing 256 bits.
+func TestValidateFeeSum(t *testing.T) {
+	var (
+		cheap  = types.NewTransaction(0, common.Address{0x01}, big.NewInt(1), params.TxGas, big.NewInt(1), nil)
+		pricey = types.NewTransaction(1, common.Address{0x01}, big.NewInt(1), params.TxGas, new(big.Int).Lsh(big.NewInt(1), 255), nil)
+	)
+	for i, tt := range []struct {
+		txs      types.Transactions
+		receipts types.Receipts
+		err      error
+	}{
+		{nil, nil, nil},
+		{types.Transactions{cheap}, types.Receipts{{GasUsed: params.TxGas}}, nil},
+		{types.Transactions{cheap}, nil, ErrReceiptCount},
+		{nil, types.Receipts{{GasUsed: params.TxGas}}, ErrReceiptCount},
+		{types.Transactions{cheap, pricey}, types.Receipts{{GasUsed: params.TxGas}, {GasUsed: 1}}, nil},
+		{types.Transactions{cheap, pricey}, types.Receipts{{GasUsed: params.TxGas}, {GasUsed: params.TxGas}}, ErrFeeOverflow},
+	} {
+		if err := validateFeeSum(tt.txs, tt.receipts); !errors.Is(err, tt.err) {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+}
+
+// Tests that extra-data starting with a forbidden prefix is rejected, while other
+// extra-data and empty prefixes pass.
+func TestValidateExtra(t *testing.T) {
+	config := *params.TestChainConfig
+	for i, tt := range []struct {
+		prefixes []hexutil.Bytes
+		extra    []byte
+		err      error
+	}{
+		{nil, []byte("pool"), nil},
+		{[]hexutil.Bytes{[]byte("pool")}, []byte("miner"), nil},
+		{[]hexutil.Bytes{[]byte("pool")}, []byte("po"), nil},
+		{[]hexutil.Bytes{{}}, []byte("pool"), nil},
+		{[]hexutil.Bytes{[]byte("pool")}, []byte("pool"), ErrForbiddenExtraData},
+		{[]hexutil.Bytes{[]byte("tag"), []byte("pool")}, []byte("pool/eu"), ErrForbiddenExtraData},
+	} {
+		config.ForbiddenExtraPrefixes = tt.prefixes
+		validator := NewBlockValidator(&config, nil, nil)
+		if err := validator.validateExtra(&types.Header{Number: big.NewInt(1), Extra: tt.extra}); !errors.Is(err, tt.err) {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+}
+
+// Tests that timestamps off their slot boundary by more than the tolerance are
+// rejected, either side of the boundary, and that chains without a slot schedule
+// skip the check.
+func TestValidateSlot(t *testing.T) {
+	var (
+		testdb = rawdb.NewMemoryDatabase()
+		gspec  = &Genesis{Config: params.TestChainConfig, Timestamp: 1000}
+	)
+	gspec.MustCommit(testdb)
+	chain, _ := NewBlockChain(testdb, nil, params.TestChainConfig, ethash.NewFaker(), vm.Config{}, nil, nil)
+	defer chain.Stop()
+
+	config := *params.TestChainConfig
+	config.BlockSlotTolerance = 2
+	for i, tt := range []struct {
+		period uint64
+		time   uint64
+		err    error
+	}{
+		{0, 1003, nil},
+		{10, 1000, nil},
+		{10, 1020, nil},
+		{10, 1022, nil},
+		{10, 1028, nil},
+		{10, 1023, ErrMisalignedSlot},
+		{10, 1027, ErrMisalignedSlot},
+		{10, 999, ErrMisalignedSlot},
+	} {
+		config.BlockSlotPeriod = tt.period
+		validator := NewBlockValidator(&config, chain, nil)
+		if err := validator.validateSlot(&types.Header{Number: big.NewInt(1), Time: tt.time}); !errors.Is(err, tt.err) {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+}
+
+// Tests that blocks including the same uncle twice are rejected, while distinct
+// uncles pass.
+func TestValidateUniqueUncles(t *testing.T) {
+	var (
+		uncle1 = &types.Header{Number: big.NewInt(8), Extra: []byte("uncle1")}
+		uncle2 = &types.Header{Number: big.NewInt(8), Extra: []byte("uncle2")}
+	)
+	for i, tt := range []struct {
+		uncles []*types.Header
+		fail   bool
+	}{
+		{nil, false},
+		{[]*types.Header{uncle1}, false},
+		{[]*types.Header{uncle1, uncle2}, false},
+		{[]*types.Header{uncle1, uncle1}, true},
+		{[]*types.Header{uncle1, uncle2, types.CopyHeader(uncle1)}, true},
+	} {
+		block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(10)}).WithBody(nil, tt.uncles)
+		if err := validateUniqueUncles(block); (err != nil) != tt.fail {
+			t.Errorf("test %d: error mismatch: have %v, want failure %t", i, err, tt.fail)
+		}
+	}
+}
+
+// Tests that blocks whose cached hash went stale after mutating the header are
+// rejected, while untouched blocks pass.
+func TestValidateHeaderHash(t *testing.T) {
+	var (
+		testdb  = rawdb.NewMemoryDatabase()
+		gspec   = &Genesis{Config: params.TestChainConfig}
+		genesis = gspec.MustCommit(testdb)
+	)
+	blocks, _ := GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), testdb, 1, nil)
+	chain, _ := NewBlockChain(testdb, nil, params.TestChainConfig, ethash.NewFaker(), vm.Config{}, nil, nil)
+	defer chain.Stop()
+
+	for i, tt := range []struct {
+		stale bool
+		err   error
+	}{
+		{false, nil},
+		{true, ErrHeaderHashMismatch},
+	} {
+		block := types.NewBlockWithHeader(blocks[0].Header()).WithBody(blocks[0].Transactions(), blocks[0].Uncles())
+		if tt.stale {
+			block.Hash()
+			block.SetRoot(common.Hash{0x01})
+		}
+		if err := chain.Validator().ValidateBody(block); !errors.Is(err, tt.err) {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+}
+
+// Tests that blocks emitting more log data than configured are rejected, with the
+// data of all logs across receipts counted together.
+func TestValidateLogDataSize(t *testing.T) {
+	receipts := types.Receipts{
+		{Logs: []*types.Log{{Data: make([]byte, 100)}, {Data: make([]byte, 50)}}},
+		{Logs: []*types.Log{{Data: make([]byte, 50)}}},
+		{},
+	}
+	config := *params.TestChainConfig
+	for _, tt := range []struct {
+		limit uint64
+		err   error
+	}{
+		{0, nil},
+		{1000, nil},
+		{200, nil},
+		{199, ErrLogDataTooLarge},
+		{1, ErrLogDataTooLarge},
+	} {
+		config.MaxLogDataBytes = tt.limit
+		validator := NewBlockValidator(&config, nil, nil)
+		if err := validator.validateLogDataSize(receipts); !errors.Is(err, tt.err) {
+			t.Errorf("limit %d: error mismatch: have %v, want %v", tt.limit, err, tt.err)
+		}
+	}
+}
+
+// Tests that blocks further ahead of their parent than configured are rejected,
+// while unknown parents and chains without a limit skip the check.
+func TestValidateTimeGap(t *testing.T) {
+	var (
+		testdb  = rawdb.NewMemoryDatabase()
+		gspec   = &Genesis{Config: params.TestChainConfig, Timestamp: 1000}
+		genesis = gspec.MustCommit(testdb)
+	)
+	chain, _ := NewBlockChain(testdb, nil, params.TestChainConfig, ethash.NewFaker(), vm.Config{}, nil, nil)
+	defer chain.Stop()
+
+	config := *params.TestChainConfig
+	for i, tt := range []struct {
+		limit  uint64
+		parent common.Hash
+		time   uint64
+		err    error
+	}{
+		{0, genesis.Hash(), 5000, nil},
+		{60, genesis.Hash(), 1030, nil},
+		{60, genesis.Hash(), 1060, nil},
+		{60, genesis.Hash(), 1061, ErrBlockTimeGap},
+		{60, common.Hash{0x01}, 5000, nil},
+	} {
+		config.MaxBlockTimeGap = tt.limit
+		validator := NewBlockValidator(&config, chain, nil)
+		header := &types.Header{ParentHash: tt.parent, Number: big.NewInt(1), Time: tt.time}
+		if err := validator.validateTimeGap(header); !errors.Is(err, tt.err) {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+}
+
+// Tests that blocks whose timestamp doesn't advance past their parent's are
+// rejected, while unknown parents are left to the ancestry check.
+func TestValidateTimestamp(t *testing.T) {
+	var (
+		testdb  = rawdb.NewMemoryDatabase()
+		gspec   = &Genesis{Config: params.TestChainConfig, Timestamp: 1000}
+		genesis = gspec.MustCommit(testdb)
+	)
+	chain, _ := NewBlockChain(testdb, nil, params.TestChainConfig, ethash.NewFaker(), vm.Config{}, nil, nil)
+	defer chain.Stop()
+
+	validator := NewBlockValidator(params.TestChainConfig, chain, nil)
+	for i, tt := range []struct {
+		parent common.Hash
+		time   uint64
+		err    error
+	}{
+		{genesis.Hash(), 1001, nil},
+		{genesis.Hash(), 1000, ErrNonMonotonicTimestamp},
+		{genesis.Hash(), 999, ErrNonMonotonicTimestamp},
+		{common.Hash{0x01}, 999, nil},
+	} {
+		header := &types.Header{ParentHash: tt.parent, Number: big.NewInt(1), Time: tt.time}
+		if err := validator.validateTimestamp(header); !errors.Is(err, tt.err) {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+}
+
+// Tests that gas limits moving away from the parent's by the bound divisor or
+// more, or dropping below the minimum, are rejected, unless the chain runs clique.
+func TestValidateGasLimit(t *testing.T) {
+	clique := *params.TestChainConfig
+	clique.Clique = &params.CliqueConfig{Period: 1, Epoch: 30000}
+
+	for i, tt := range []struct {
+		config   *params.ChainConfig
+		parent   uint64
+		gasLimit uint64
+		err      error
+	}{
+		{params.TestChainConfig, 8000000, 8000000, nil},
+		{params.TestChainConfig, 8000000, 8000000 + 31249, nil},
+		{params.TestChainConfig, 8000000, 8000000 - 31249, nil},
+		{params.TestChainConfig, 8000000, 8000000 + 31250, ErrInvalidGasLimit},
+		{params.TestChainConfig, 8000000, 8000000 - 31250, ErrInvalidGasLimit},
+		{params.TestChainConfig, 5010, 5000, nil},
+		{params.TestChainConfig, 5010, 4999, ErrInvalidGasLimit},
+		{&clique, 8000000, 16000000, nil},
+	} {
+		var (
+			testdb  = rawdb.NewMemoryDatabase()
+			gspec   = &Genesis{Config: params.TestChainConfig, GasLimit: tt.parent}
+			genesis = gspec.MustCommit(testdb)
+		)
+		chain, _ := NewBlockChain(testdb, nil, params.TestChainConfig, ethash.NewFaker(), vm.Config{}, nil, nil)
+
+		validator := NewBlockValidator(tt.config, chain, nil)
+		header := &types.Header{ParentHash: genesis.Hash(), Number: big.NewInt(1), GasLimit: tt.gasLimit}
+		if err := validator.validateGasLimit(header); !errors.Is(err, tt.err) {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+		chain.Stop()
+	}
+}
+
+// Tests that state validation honours its context: a live context validates the
+// block, while a cancelled one abandons the checks without recording a failure.
+func TestValidateStateWithContext(t *testing.T) {
+	var (
+		testdb  = rawdb.NewMemoryDatabase()
+		gspec   = &Genesis{Config: params.TestChainConfig}
+		genesis = gspec.MustCommit(testdb)
+	)
+	blocks, _ := GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), testdb, 1, nil)
+	chain, _ := NewBlockChain(testdb, nil, params.TestChainConfig, ethash.NewFaker(), vm.Config{}, nil, nil)
+	defer chain.Stop()
+
+	statedb, err := state.New(genesis.Root(), chain.stateCache, nil)
+	if err != nil {
+		t.Fatalf("failed to open parent state: %v", err)
+	}
+	receipts, _, usedGas, err := chain.processor.Process(blocks[0], statedb, vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to process block: %v", err)
+	}
+	validator := chain.Validator().(*BlockValidator)
+	if err := validator.ValidateStateWithContext(context.Background(), blocks[0], statedb, receipts, usedGas); err != nil {
+		t.Fatalf("valid block rejected: %v", err)
+	}
+	// Keep all the validation workers busy so the cancellation wins the race
+	release := make(chan struct{})
+	defer close(release)
+busy:
+	for {
+		select {
+		case validationPool.tasks <- func() { <-release }:
+		case <-time.After(50 * time.Millisecond):
+			break busy
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := validator.ValidateStateWithContext(ctx, blocks[0], statedb, receipts, usedGas); err != context.Canceled {
+		t.Errorf("error mismatch: have %v, want %v", err, context.Canceled)
+	}
+	if err := validator.LastValidationError(); err != nil {
+		t.Errorf("cancelled validation recorded as failure: %v", err)
+	}
+}
+
+// Tests that each state mismatch is reported as a typed error naming the failed
+// check, while the processed block itself passes.
+func TestStateValidationErrorKinds(t *testing.T) {
+	var (
+		testdb  = rawdb.NewMemoryDatabase()
+		gspec   = &Genesis{Config: params.TestChainConfig}
+		genesis = gspec.MustCommit(testdb)
+	)
+	blocks, _ := GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), testdb, 1, nil)
+	chain, _ := NewBlockChain(testdb, nil, params.TestChainConfig, ethash.NewFaker(), vm.Config{}, nil, nil)
+	defer chain.Stop()
+
+	statedb, err := state.New(genesis.Root(), chain.stateCache, nil)
+	if err != nil {
+		t.Fatalf("failed to open parent state: %v", err)
+	}
+	receipts, _, usedGas, err := chain.processor.Process(blocks[0], statedb, vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to process block: %v", err)
+	}
+	validator := chain.Validator().(*BlockValidator)
+	for _, tt := range []struct {
+		name   string
+		tamper func(header *types.Header)
+		used   uint64
+		fail   bool
+		kind   StateValidationKind
+	}{
+		{"valid", func(*types.Header) {}, usedGas, false, 0},
+		{"gasUsed", func(*types.Header) {}, usedGas + 1, true, ValidationGasUsed},
+		{"bloom", func(h *types.Header) { h.Bloom = types.Bloom{0x01} }, usedGas, true, ValidationBloom},
+		{"receiptRoot", func(h *types.Header) { h.ReceiptHash = common.Hash{0x01} }, usedGas, true, ValidationReceiptRoot},
+		{"stateRoot", func(h *types.Header) { h.Root = common.Hash{0x01} }, usedGas, true, ValidationStateRoot},
+	} {
+		header := blocks[0].Header()
+		tt.tamper(header)
+		block := types.NewBlockWithHeader(header).WithBody(blocks[0].Transactions(), blocks[0].Uncles())
+
+		err := validator.ValidateState(block, statedb, receipts, tt.used)
+		if !tt.fail {
+			if err != nil {
+				t.Errorf("%s: valid block rejected: %v", tt.name, err)
+			}
+			continue
+		}
+		var stateErr *StateValidationError
+		if !errors.As(err, &stateErr) || stateErr.Kind != tt.kind {
+			t.Errorf("%s: error mismatch: have %v, want %v mismatch", tt.name, err, tt.kind)
+		}
+	}
+}
+
+// Tests that the default gas limit target reproduces the original 3/2 arithmetic.
+func TestCalcGasLimitDefaultTarget(t *testing.T) {
+	legacy := func(parent *types.Block, gasFloor, gasCeil uint64) uint64 {
+		contrib := (parent.GasUsed() + parent.GasUsed()/2) / params.GasLimitBoundDivisor
+		decay := parent.GasLimit()/params.GasLimitBoundDivisor - 1
+
+		limit := parent.GasLimit() - decay + contrib
+		if limit < params.MinGasLimit {
+			limit = params.MinGasLimit
+		}
+		if limit < gasFloor {
+			limit = parent.GasLimit() + decay
+			if limit > gasFloor {
+				limit = gasFloor
+			}
+		} else if limit > gasCeil {
+			limit = parent.GasLimit() - decay
+			if limit < gasCeil {
+				limit = gasCeil
+			}
+		}
+		return limit
+	}
+	for _, gasLimit := range []uint64{params.MinGasLimit, 8000000, 30000000} {
+		for gasUsed := uint64(0); gasUsed <= gasLimit; gasUsed += gasLimit/97 + 1 {
+			parent := types.NewBlockWithHeader(&types.Header{GasLimit: gasLimit, GasUsed: gasUsed})
+			want := legacy(parent, 8000000, 20000000)
+			if have := CalcGasLimit(parent, 8000000, 20000000); have != want {
+				t.Errorf("limit %d, used %d: have %d, want %d", gasLimit, gasUsed, have, want)
+			}
+			if have := CalcGasLimitWithTarget(parent, 8000000, 20000000, GasLimitTarget{}); have != want {
+				t.Errorf("limit %d, used %d, zero target: have %d, want %d", gasLimit, gasUsed, have, want)
+			}
+		}
+	}
+}
+
+// Tests that the London gas limit moves towards the ceil by the maximum step.
+func TestCalcGasLimit1559(t *testing.T) {
+	for i, tc := range []struct {
+		pGasLimit uint64
+		gasCeil   uint64
+		want      uint64
+	}{
+		{20000000, 25000000, 20078124}, // Increases by parent/256-1
+		{20000000, 15000000, 19921876}, // Decreases by parent/256-1
+		{20000000, 20000000, 20000000}, // Stays at the ceil
+		{20000000, 20050000, 20050000}, // Doesn't overshoot the ceil upwards
+		{20000000, 19950000, 19950000}, // Doesn't overshoot the ceil downwards
+		{5100, 1000, 5082},             // Ceil below the minimum is raised to it
+	} {
+		parent := types.NewBlockWithHeader(&types.Header{GasLimit: tc.pGasLimit})
+		if have := CalcGasLimit1559(parent, tc.gasCeil); have != tc.want {
+			t.Errorf("test %d: have %d want %d", i, have, tc.want)
+		}
+	}
+}
+
+// Benchmarks the transaction root derivation with fresh and pooled stack tries.
+func BenchmarkDeriveTxRoot(b *testing.B) {
+	txs := make(types.Transactions, 200)
+	for i := range txs {
+		txs[i] = types.NewTransaction(uint64(i), common.Address{0x01}, big.NewInt(1), params.TxGas, big.NewInt(1), nil)
+	}
+	b.Run("fresh", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			types.DeriveSha(txs, trie.NewStackTrie(nil))
+		}
+	})
+	b.Run("pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			deriveSha(txs)
+		}
+	})
+}
+
+// Benchmarks body validation of blocks carrying the maximum number of uncles.
+func BenchmarkValidateBodyUncles(b *testing.B) {
+	var (
+		testdb  = rawdb.NewMemoryDatabase()
+		gspec   = &Genesis{Config: params.TestChainConfig}
+		genesis = gspec.MustCommit(testdb)
+	)
+	blocks, _ := GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), testdb, 16, func(i int, block *BlockGen) {
+		// Include the previous two blocks with modified extra-data as uncles
+		for j, extra := range []string{"uncle-a", "uncle-b"} {
+			if i > j+1 {
+				uncle := block.PrevBlock(i - j - 1).Header()
+				uncle.Extra = []byte(extra)
+				block.AddUncle(uncle)
+			}
+		}
+	})
+	chain, _ := NewBlockChain(testdb, nil, params.TestChainConfig, ethash.NewFaker(), vm.Config{}, nil, nil)
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks[:len(blocks)-1]); err != nil {
+		b.Fatalf("failed to insert chain: %v", err)
+	}
+	block := blocks[len(blocks)-1]
+	if len(block.Uncles()) != 2 {
+		b.Fatalf("uncle count mismatch: have %d, want 2", len(block.Uncles()))
+	}
+	validator := chain.Validator()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := validator.ValidateBody(block); err != nil {
+			b.Fatalf("failed to validate body: %v", err)
+		}
+	}
+}
+
+// Tests that scheduling funcs on a pool with all workers busy doesn't block the
+// caller, and that every func runs even if the caller stops collecting results.
+func TestWorkerPoolBusy(t *testing.T) {
+	pool := newWorkerPool(1)
+
+	release := make(chan struct{})
+	pool.tasks <- func() { <-release }
+
+	var ran int32
+	count := func() error {
+		atomic.AddInt32(&ran, 1)
+		return nil
+	}
+	funcs := []func() error{count, count}
+	scheduled := make(chan (<-chan error))
+	go func() { scheduled <- pool.run(funcs) }()
+
+	var results <-chan error
+	select {
+	case results = <-scheduled:
+	case <-time.After(time.Second):
+		t.Fatal("run blocked waiting for a free worker")
+	}
+	select {
+	case err := <-results:
+		t.Fatalf("func ran while the worker was busy: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	for i := range funcs {
+		select {
+		case err := <-results:
+			if err != nil {
+				t.Fatalf("func %d failed: %v", i, err)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("func %d never ran", i)
+		}
+	}
+	// Abandon a batch while the worker is busy and make sure all of it still runs
+	release = make(chan struct{})
+	pool.tasks <- func() { <-release }
+
+	pool.run(funcs)
+	close(release)
+
+	for start := time.Now(); atomic.LoadInt32(&ran) != int32(2*len(funcs)); {
+		if time.Since(start) > time.Second {
+			t.Fatalf("run count mismatch: have %d, want %d", atomic.LoadInt32(&ran), 2*len(funcs))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// Benchmarks running a batch of validation funcs on a goroutine each versus on
+// the shared worker pool.
+func BenchmarkValidationFuncs(b *testing.B) {
+	funcs := make([]func() error, 9)
+	for i := range funcs {
+		funcs[i] = func() error { return nil }
+	}
+	wait := func(b *testing.B, results <-chan error) {
+		for i := 0; i < len(funcs); i++ {
+			if err := <-results; err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	b.Run("goroutines", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			results := make(chan error, len(funcs))
+			for _, f := range funcs {
+				f := f
+				go func() { results <- f() }()
+			}
+			wait(b, results)
+		}
+	})
+	b.Run("pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			wait(b, validationPool.run(funcs))
+		}
+	})
+}
@@ -1683,6 +1683,21 @@ func (bc *BlockChain) InsertChain(chain types.Blocks) (int, error) {
 	return n, err
 }
 
+// WriteLocalBlock validates the body of a block sealed by the local miner and
+// writes it together with its state. Its uncles and transaction root were derived
+// from trusted state while assembling it, so body validation skips recomputing
+// them, while the remaining body checks still run. It must never be used for
+// blocks received from the network.
+func (bc *BlockChain) WriteLocalBlock(block *types.Block, receipts []*types.Receipt, logs []*types.Log, state *state.StateDB, emitHeadEvent bool) (WriteStatus, error) {
+	if validator, ok := bc.validator.(*BlockValidator); ok {
+		defer validator.trustLocal(block)()
+	}
+	if err := bc.validator.ValidateBody(block); err != nil {
+		return NonStatTy, err
+	}
+	return bc.WriteBlockWithState(block, receipts, logs, state, emitHeadEvent)
+}
+
 // InsertChainWithoutSealVerification works exactly the same
 // except for seal verification, seal verification is omitted
 func (bc *BlockChain) InsertChainWithoutSealVerification(block *types.Block) (int, error) {
@@ -2217,6 +2232,15 @@ func (bc *BlockChain) reorg(oldBlock, newBlock *types.Block) error {
 			return fmt.Errorf("invalid new chain")
 		}
 	}
+	// Refuse to rewrite more history than the chain allows, before touching the
+	// canonical chain
+	depth := len(oldChain)
+	if len(newChain) > depth {
+		depth = len(newChain)
+	}
+	if err := validateReorgDepth(bc.chainConfig, commonBlock.NumberU64(), commonBlock.NumberU64()+uint64(depth)); err != nil {
+		return err
+	}
 	// Ensure the user sees large reorgs
 	if len(oldChain) > 0 && len(newChain) > 0 {
 		logFn := log.Info
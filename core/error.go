@@ -18,6 +18,7 @@ package core
 
 import (
 	"errors"
+	"fmt"
 
 	"PureChain/core/types"
 )
@@ -31,6 +32,102 @@ var (
 
 	// ErrNoGenesis is returned when there is no Genesis Block.
 	ErrNoGenesis = errors.New("genesis not found in chain")
+
+	// ErrHeaderHashMismatch is returned if the cached hash of a block doesn't match
+	// the hash of its header fields.
+	ErrHeaderHashMismatch = errors.New("block hash mismatch")
+
+	// ErrExtraDataTooLong is returned if a block's extra-data exceeds the maximum
+	// size allowed by the protocol.
+	ErrExtraDataTooLong = errors.New("extra-data too long")
+
+	// ErrForbiddenExtraData is returned if a block's extra-data starts with one of
+	// the prefixes forbidden by the chain configuration.
+	ErrForbiddenExtraData = errors.New("forbidden extra-data prefix")
+
+	// ErrMisalignedSlot is returned if a block's timestamp doesn't fall on the
+	// slot schedule of the chain configuration.
+	ErrMisalignedSlot = errors.New("timestamp not aligned to block slot")
+
+	// ErrBlockTimeGap is returned if a block's timestamp is further ahead of its
+	// parent's than allowed by the chain configuration.
+	ErrBlockTimeGap = errors.New("block time gap too large")
+
+	// ErrNonceReuse is returned if a block reuses the nonce of a recently seen
+	// block and the nonce reuse diagnostic is configured to reject such blocks.
+	ErrNonceReuse = errors.New("block nonce recently seen")
+
+	// ErrCoinbaseNotSealer is returned if the chain requires block rewards to go
+	// to the sealer, but a block's coinbase differs from its recovered sealer.
+	ErrCoinbaseNotSealer = errors.New("coinbase is not the block sealer")
+
+	// ErrZeroCoinbase is returned if a proof-of-work block pays its reward to the
+	// zero address after the chain started forbidding it.
+	ErrZeroCoinbase = errors.New("zero coinbase")
+
+	// ErrLogDataTooLarge is returned if the logs emitted by a block carry more data
+	// than allowed by the chain configuration.
+	ErrLogDataTooLarge = errors.New("log data too large")
+
+	// ErrFeeOverflow is returned if the fees paid by a block's transactions don't
+	// fit in 256 bits, which indicates a malformed block or broken fee handling.
+	ErrFeeOverflow = errors.New("transaction fees overflow")
+
+	// ErrReceiptCount is returned if the number of receipts produced by a block
+	// differs from the number of its transactions.
+	ErrReceiptCount = errors.New("receipt count mismatch")
+
+	// ErrNonMonotonicTimestamp is returned if a block's timestamp isn't strictly
+	// greater than its parent's.
+	ErrNonMonotonicTimestamp = errors.New("timestamp not after parent")
+
+	// ErrGasUsedExceedsLimit is returned if a block's header claims to have used
+	// more gas than its gas limit allows.
+	ErrGasUsedExceedsLimit = errors.New("gas used exceeds gas limit")
+
+	// ErrInvalidGasLimit is returned if a block's gas limit moved further away from
+	// its parent's than the protocol allows.
+	ErrInvalidGasLimit = errors.New("invalid gas limit")
+
+	// ErrTooManyTransactions is returned if a block carries more transactions than
+	// allowed by the chain configuration.
+	ErrTooManyTransactions = errors.New("too many transactions in block")
+
+	// ErrUnauthorizedSealer is returned if the chain restricts who may seal blocks
+	// and a block's coinbase isn't among the authorized sealers.
+	ErrUnauthorizedSealer = errors.New("unauthorized sealer")
+
+	// ErrUncleTooOld is returned if a block includes an uncle further below it
+	// than the maximum uncle depth.
+	ErrUncleTooOld = errors.New("uncle too old")
+
+	// ErrUncleTooYoung is returned if a block includes an uncle that isn't below
+	// it, i.e. numbered at or above the block itself.
+	ErrUncleTooYoung = errors.New("uncle too young")
+
+	// ErrReorgTooDeep is returned if switching to a new chain would rewrite more
+	// blocks than allowed by the chain configuration.
+	ErrReorgTooDeep = errors.New("reorg too deep")
+
+	// ErrOversizedBlock is returned if a block's RLP encoding is larger than
+	// allowed by the chain configuration.
+	ErrOversizedBlock = errors.New("oversized block")
+
+	// ErrInvalidDifficulty is returned if a block's difficulty doesn't match the
+	// one the consensus engine computes from its parent.
+	ErrInvalidDifficulty = errors.New("invalid difficulty")
+
+	// ErrCumulativeGasDecreased is returned if the cumulative gas used recorded in
+	// a block's receipts goes down from one receipt to the next.
+	ErrCumulativeGasDecreased = errors.New("cumulative gas used decreased")
+
+	// ErrSenderHasCode is returned if a transaction is sent from an account with
+	// deployed code, which EIP-3607 forbids.
+	ErrSenderHasCode = errors.New("sender has code")
+
+	// ErrValidationTimeout is returned if the state of a block couldn't be
+	// validated within the configured deadline.
+	ErrValidationTimeout = errors.New("block validation timed out")
 )
 
 // List of evm-call-message pre-checking errors. All state transition messages will
@@ -68,7 +165,68 @@ var (
 	// than required to start the invocation.
 	ErrIntrinsicGas = errors.New("intrinsic gas too low")
 
+	// ErrMaxInitCodeSizeExceeded is returned if creation transaction provides the
+	// init code bigger than init code size limit.
+	ErrMaxInitCodeSizeExceeded = errors.New("max initcode size exceeded")
+
 	// ErrTxTypeNotSupported is returned if a transaction is not supported in the
 	// current network configuration.
 	ErrTxTypeNotSupported = types.ErrTxTypeNotSupported
 )
+
+// StateValidationKind identifies the check of the state validation a block failed.
+type StateValidationKind int
+
+const (
+	ValidationGasUsed       StateValidationKind = iota // Gas used by the block doesn't match the processed one
+	ValidationBloom                                    // Bloom doesn't match the processed logs
+	ValidationReceiptRoot                              // Receipt root doesn't match the processed receipts
+	ValidationStateRoot                                // State root doesn't match the processed state
+	ValidationCumulativeGas                            // Gas used doesn't match the last receipt's cumulative gas
+)
+
+// String implements fmt.Stringer.
+func (k StateValidationKind) String() string {
+	switch k {
+	case ValidationGasUsed:
+		return "gasUsed"
+	case ValidationBloom:
+		return "bloom"
+	case ValidationReceiptRoot:
+		return "receiptRoot"
+	case ValidationStateRoot:
+		return "stateRoot"
+	case ValidationCumulativeGas:
+		return "cumulativeGas"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(k))
+	}
+}
+
+// StateValidationError is returned if the result of processing a block doesn't
+// match what the block claims. Have holds the value from the block, Want the one
+// obtained by processing it.
+type StateValidationError struct {
+	Kind        StateValidationKind
+	Have        interface{}
+	Want        interface{}
+	BlockNumber uint64
+}
+
+// Error implements error.
+func (e *StateValidationError) Error() string {
+	switch e.Kind {
+	case ValidationGasUsed:
+		return fmt.Sprintf("invalid gas used (remote: %d local: %d)", e.Have, e.Want)
+	case ValidationBloom:
+		return fmt.Sprintf("invalid bloom (remote: %x  local: %x)", e.Have, e.Want)
+	case ValidationReceiptRoot:
+		return fmt.Sprintf("invalid receipt root hash (remote: %x local: %x)", e.Have, e.Want)
+	case ValidationStateRoot:
+		return fmt.Sprintf("invalid merkle root (remote: %x local: %x)", e.Have, e.Want)
+	case ValidationCumulativeGas:
+		return fmt.Sprintf("invalid receipt gas sum (remote: %d local: %d)", e.Have, e.Want)
+	default:
+		return fmt.Sprintf("invalid %v (remote: %v local: %v)", e.Kind, e.Have, e.Want)
+	}
+}
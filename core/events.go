@@ -41,3 +41,11 @@ type ChainSideEvent struct {
 }
 
 type ChainHeadEvent struct{ Block *types.Block }
+
+// ValidationFailure is posted when a processed block fails state validation.
+type ValidationFailure struct {
+	BlockHash common.Hash
+	Number    uint64
+	Kind      string // Check the block failed, e.g. "stateRoot" or "timeout"
+	Err       error
+}
@@ -17,6 +17,7 @@
 package core
 
 import (
+	"errors"
 	"math/big"
 	"testing"
 
@@ -24,6 +25,7 @@ import (
 	"PureChain/consensus"
 	"PureChain/consensus/ethash"
 	"PureChain/core/rawdb"
+	"PureChain/core/state"
 	"PureChain/core/types"
 	"PureChain/core/vm"
 	"PureChain/crypto"
@@ -150,3 +152,35 @@ func GenerateBadBlock(parent *types.Block, engine consensus.Engine, txs types.Tr
 	// Assemble and return the final block for sealing
 	return types.NewBlock(header, txs, nil, receipts, trie.NewStackTrie(nil))
 }
+
+// Tests that transactions sent from accounts with code are rejected against the
+// state before the transaction once EIP-3607 activates.
+func TestApplyTransactionSenderWithCode(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+
+	config := *params.TestChainConfig
+	config.EIP3607Block = big.NewInt(2)
+
+	apply := func(number int64, code []byte) error {
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		statedb.AddBalance(sender, big.NewInt(params.Ether))
+		statedb.SetCode(sender, code)
+
+		header := &types.Header{Number: big.NewInt(number), GasLimit: params.TxGas, Difficulty: big.NewInt(1)}
+		tx, _ := types.SignTx(types.NewTransaction(0, common.Address{0x01}, big.NewInt(1), params.TxGas, big.NewInt(1), nil), types.MakeSigner(&config, header.Number), key)
+
+		var usedGas uint64
+		_, err := ApplyTransaction(&config, nil, &common.Address{}, new(GasPool).AddGas(header.GasLimit), statedb, header, tx, &usedGas, vm.Config{})
+		return err
+	}
+	if err := apply(1, []byte{0x60, 0x00}); err != nil {
+		t.Errorf("sender with code rejected before the fork: %v", err)
+	}
+	if err := apply(2, []byte{0x60, 0x00}); !errors.Is(err, ErrSenderHasCode) {
+		t.Errorf("error mismatch: have %v, want %v", err, ErrSenderHasCode)
+	}
+	if err := apply(2, nil); err != nil {
+		t.Errorf("sender without code rejected: %v", err)
+	}
+}
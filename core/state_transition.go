@@ -206,6 +206,10 @@ func (st *StateTransition) preCheck() error {
 			return fmt.Errorf("%w: address %v, tx: %d state: %d", ErrNonceTooLow,
 				st.msg.From().Hex(), msgNonce, stNonce)
 		}
+		// Make sure the sender has no deployed code once EIP-3607 is active
+		if st.evm.ChainConfig().IsEIP3607(st.evm.Context.BlockNumber) && st.state.GetCodeSize(st.msg.From()) > 0 {
+			return fmt.Errorf("%w: address %v", ErrSenderHasCode, st.msg.From().Hex())
+		}
 	}
 	return st.buyGas()
 }
@@ -270,6 +270,34 @@ func TestInvalidTransactions(t *testing.T) {
 	}
 }
 
+// Tests that transactions sent from accounts with deployed code are rejected once
+// EIP-3607 is active.
+func TestTransactionSenderWithCode(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := &testBlockChain{statedb, 10000000, new(event.Feed)}
+
+	config := *params.TestChainConfig
+	config.EIP3607Block = big.NewInt(0)
+	pool := NewTxPool(testTxPoolConfig, &config, blockchain)
+	defer pool.Stop()
+
+	key, _ := crypto.GenerateKey()
+	tx := transaction(0, 100000, key)
+	from, _ := deriveSender(tx)
+
+	pool.currentState.AddBalance(from, big.NewInt(0xffffffffffffff))
+	pool.currentState.SetCode(from, []byte{0x60, 0x00})
+	if err := pool.AddRemote(tx); !errors.Is(err, ErrSenderHasCode) {
+		t.Errorf("error mismatch: have %v, want %v", err, ErrSenderHasCode)
+	}
+	pool.currentState.SetCode(from, nil)
+	if err := pool.AddRemote(tx); err != nil {
+		t.Errorf("sender without code rejected: %v", err)
+	}
+}
+
 func TestTransactionQueue(t *testing.T) {
 	t.Parallel()
 
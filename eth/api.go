@@ -86,6 +86,12 @@ func (api *PublicMinerAPI) Mining() bool {
 	return api.e.IsMining()
 }
 
+// GetMinAcceptedGasPrice returns the lowest gas price of the transactions the
+// miner currently includes in its blocks.
+func (api *PublicMinerAPI) GetMinAcceptedGasPrice() *hexutil.Big {
+	return (*hexutil.Big)(api.e.txPool.GasPrice())
+}
+
 // PrivateMinerAPI provides private RPC methods to control the miner.
 // These methods can be abused by external users and must be considered insecure for use by untrusted users.
 type PrivateMinerAPI struct {
@@ -144,6 +150,25 @@ func (api *PrivateMinerAPI) SetRecommitInterval(interval int) {
 	api.e.Miner().SetRecommitInterval(time.Duration(interval) * time.Millisecond)
 }
 
+// TxSelectionPolicy describes how the miner picks and orders transactions for
+// the blocks it creates.
+type TxSelectionPolicy struct {
+	Ordering          string           `json:"ordering"`          // Ordering of the transactions within each group
+	MinGasPrice       *hexutil.Big     `json:"minGasPrice"`       // Minimum gas price accepted for inclusion
+	PriorityAddresses []common.Address `json:"priorityAddresses"` // Senders whose transactions are included before all others
+}
+
+// GetTxSelectionPolicy returns the transaction selection policy currently used
+// by the miner. Transactions of the local accounts are committed first, then the
+// remote ones, both ordered by gas price while respecting the sender nonces.
+func (api *PrivateMinerAPI) GetTxSelectionPolicy() *TxSelectionPolicy {
+	return &TxSelectionPolicy{
+		Ordering:          "gasPriceAndNonce",
+		MinGasPrice:       (*hexutil.Big)(api.e.txPool.GasPrice()),
+		PriorityAddresses: api.e.txPool.Locals(),
+	}
+}
+
 // PrivateAdminAPI is the collection of Ethereum full node-related APIs
 // exposed over the private admin endpoint.
 type PrivateAdminAPI struct {
@@ -311,6 +336,26 @@ func (api *PrivateDebugAPI) Preimage(ctx context.Context, hash common.Hash) (hex
 	return nil, errors.New("unknown preimage")
 }
 
+// GetLastValidationError returns the most recent failure of the block validator,
+// or nil if no block failed validation since the last clearing.
+func (api *PrivateDebugAPI) GetLastValidationError() (*core.ValidationError, error) {
+	validator, ok := api.eth.blockchain.Validator().(*core.BlockValidator)
+	if !ok {
+		return nil, errors.New("validator doesn't track failures")
+	}
+	return validator.LastValidationError(), nil
+}
+
+// ClearLastValidationError forgets the most recent failure of the block validator.
+func (api *PrivateDebugAPI) ClearLastValidationError() error {
+	validator, ok := api.eth.blockchain.Validator().(*core.BlockValidator)
+	if !ok {
+		return errors.New("validator doesn't track failures")
+	}
+	validator.ClearLastValidationError()
+	return nil
+}
+
 // BadBlockArgs represents the entries in the list returned when bad blocks are queried.
 type BadBlockArgs struct {
 	Hash  common.Hash            `json:"hash"`
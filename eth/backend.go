@@ -130,6 +130,7 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	ethashConfig.NotifyFull = config.Miner.NotifyFull
 	inihashConfig := config.Inihash
 	inihashConfig.NotifyFull = config.Miner.NotifyFull
+	inihashConfig.StratumAddr = config.Miner.StratumAddr
 	// Assemble the Ethereum object
 	chainDb, err := stack.OpenDatabaseWithFreezer("chaindata", config.DatabaseCache, config.DatabaseHandles, config.DatabaseFreezer, "eth/db/chaindata/", false)
 	if err != nil {
@@ -210,6 +211,12 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	if err != nil {
 		return nil, err
 	}
+	// Enable the optional block validation checks requested by the user
+	if validator, ok := eth.blockchain.Validator().(*core.BlockValidator); ok {
+		validator.SetNonceReuseCheck(config.NonceReuseWindow, config.RejectNonceReuse)
+		validator.SetValidationTimeout(config.ValidationTimeout)
+		validator.SetDumpOnStateMismatch(config.DumpOnStateMismatch)
+	}
 	// Rewind the chain in case of an incompatible config upgrade.
 	if compat, ok := genesisErr.(*params.ConfigCompatError); ok {
 		log.Warn("Rewinding chain to upgrade configuration", "err", compat)
@@ -259,7 +266,9 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	}
 
 	eth.miner = miner.New(eth, &config.Miner, chainConfig, eth.EventMux(), eth.engine, eth.isLocalBlock)
-	eth.miner.SetExtra(makeExtraData(config.Miner.ExtraData))
+	if err := eth.miner.SetExtra(makeExtraData(config.Miner.ExtraData)); err != nil {
+		log.Warn("Ignoring configured miner extra-data", "err", err)
+	}
 
 	gpoParams := config.GPO
 	if gpoParams.Default == nil {
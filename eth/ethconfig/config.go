@@ -20,6 +20,7 @@ package ethconfig
 import (
 	"PureChain/consensus/dpos"
 	"PureChain/consensus/inihash"
+	"errors"
 	"math/big"
 	"os"
 	"os/user"
@@ -83,8 +84,8 @@ var Defaults = Config{
 	TriesInMemory:           128,
 	SnapshotCache:           102,
 	Miner: miner.Config{
-		GasFloor:      15000000,
-		GasCeil:       30000000,
+		GasFloor:      params.MainnetGasFloor,
+		GasCeil:       params.MainnetGasCeil,
 		GasPrice:      big.NewInt(params.GWei),
 		Recommit:      3 * time.Second,
 		DelayLeftOver: 500 * time.Millisecond,
@@ -142,6 +143,12 @@ type Config struct {
 	// Whitelist of required block number -> hash values to accept
 	Whitelist map[uint64]common.Hash `toml:"-"`
 
+	// Block validation options
+	NonceReuseWindow    uint64        `toml:",omitempty"` // Number of recent blocks to flag reused block nonces in (0 = disabled)
+	RejectNonceReuse    bool          `toml:",omitempty"` // Whether blocks reusing a recent nonce are rejected instead of logged
+	ValidationTimeout   time.Duration `toml:",omitempty"` // Maximum time to spend validating the state of a block (0 = unlimited)
+	DumpOnStateMismatch bool          `toml:",omitempty"` // Whether to log the transactions of blocks with a mismatching state root
+
 	// Light client options
 	LightServ          int  `toml:",omitempty"` // Maximum percentage of time allowed for serving LES requests
 	LightIngress       int  `toml:",omitempty"` // Incoming bandwidth limit for light servers
@@ -216,6 +223,27 @@ type Config struct {
 	OverrideBerlin *big.Int `toml:",omitempty"`
 }
 
+// EngineForConfig creates the consensus engine selected by the chain configuration
+// with default settings: clique for proof-of-authority chains and inihash for the
+// proof-of-work ones. Engines depending on a running node, like parlia and dpos,
+// have to be created through CreateConsensusEngine.
+func EngineForConfig(config *params.ChainConfig, db ethdb.Database) (consensus.Engine, error) {
+	switch {
+	case config == nil:
+		return nil, errors.New("missing chain config")
+	case config.Clique != nil:
+		return clique.New(config.Clique, db), nil
+	case config.Parlia != nil || config.Dpos != nil:
+		return nil, errors.New("consensus engine requires a running node")
+	case config.Inihash != nil:
+		engine := inihash.New(inihash.Config{}, nil, false, config.ChainID)
+		engine.SetThreads(-1) // Disable CPU mining
+		return engine, nil
+	default:
+		return nil, errors.New("no consensus engine configured")
+	}
+}
+
 // CreateConsensusEngine creates a consensus engine for the given chain configuration.
 func CreateConsensusEngine(stack *node.Node, chainConfig *params.ChainConfig, config *ethash.Config, iniConfig *inihash.Config, notify []string, noverify bool, db ethdb.Database, ee *ethapi.PublicBlockChainAPI, genesisHash common.Hash) consensus.Engine {
 	// If proof-of-authority is requested, set it up
@@ -249,6 +277,10 @@ func CreateConsensusEngine(stack *node.Node, chainConfig *params.ChainConfig, co
 			DatasetsOnDisk:   iniConfig.DatasetsOnDisk,
 			DatasetsLockMmap: iniConfig.DatasetsLockMmap,
 			NotifyFull:       iniConfig.NotifyFull,
+			StratumAddr:      iniConfig.StratumAddr,
+			MaxHashrate:      iniConfig.MaxHashrate,
+			SealCacheSize:    iniConfig.SealCacheSize,
+			WorkTimeout:      iniConfig.WorkTimeout,
 		}, notify, noverify, chainConfig.ChainID)
 		engine.SetThreads(-1) // Disable CPU mining
 		return engine
@@ -26,6 +26,10 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		NoPrefetch              bool
 		TxLookupLimit           uint64                 `toml:",omitempty"`
 		Whitelist               map[uint64]common.Hash `toml:"-"`
+		NonceReuseWindow        uint64                 `toml:",omitempty"`
+		RejectNonceReuse        bool                   `toml:",omitempty"`
+		ValidationTimeout       time.Duration          `toml:",omitempty"`
+		DumpOnStateMismatch     bool                   `toml:",omitempty"`
 		LightServ               int                    `toml:",omitempty"`
 		LightIngress            int                    `toml:",omitempty"`
 		LightEgress             int                    `toml:",omitempty"`
@@ -70,6 +74,10 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.NoPruning = c.NoPruning
 	enc.TxLookupLimit = c.TxLookupLimit
 	enc.Whitelist = c.Whitelist
+	enc.NonceReuseWindow = c.NonceReuseWindow
+	enc.RejectNonceReuse = c.RejectNonceReuse
+	enc.ValidationTimeout = c.ValidationTimeout
+	enc.DumpOnStateMismatch = c.DumpOnStateMismatch
 	enc.LightServ = c.LightServ
 	enc.LightIngress = c.LightIngress
 	enc.LightEgress = c.LightEgress
@@ -119,6 +127,10 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		NoPrefetch              *bool
 		TxLookupLimit           *uint64                `toml:",omitempty"`
 		Whitelist               map[uint64]common.Hash `toml:"-"`
+		NonceReuseWindow        *uint64                `toml:",omitempty"`
+		RejectNonceReuse        *bool                  `toml:",omitempty"`
+		ValidationTimeout       *time.Duration         `toml:",omitempty"`
+		DumpOnStateMismatch     *bool                  `toml:",omitempty"`
 		LightServ               *int                   `toml:",omitempty"`
 		LightIngress            *int                   `toml:",omitempty"`
 		LightEgress             *int                   `toml:",omitempty"`
@@ -182,6 +194,18 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.Whitelist != nil {
 		c.Whitelist = dec.Whitelist
 	}
+	if dec.NonceReuseWindow != nil {
+		c.NonceReuseWindow = *dec.NonceReuseWindow
+	}
+	if dec.RejectNonceReuse != nil {
+		c.RejectNonceReuse = *dec.RejectNonceReuse
+	}
+	if dec.ValidationTimeout != nil {
+		c.ValidationTimeout = *dec.ValidationTimeout
+	}
+	if dec.DumpOnStateMismatch != nil {
+		c.DumpOnStateMismatch = *dec.DumpOnStateMismatch
+	}
 	if dec.LightServ != nil {
 		c.LightServ = *dec.LightServ
 	}
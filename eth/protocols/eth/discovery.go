@@ -19,10 +19,20 @@ package eth
 import (
 	"PureChain/core"
 	"PureChain/core/forkid"
+	"PureChain/crypto"
+	"PureChain/p2p/discover"
 	"PureChain/p2p/enode"
 	"PureChain/rlp"
+	"context"
+	"errors"
+	"fmt"
+	"net"
 )
 
+// errNoForkID is returned by ProbeBootnodeForkID if the probed node's record
+// doesn't advertise the `eth` protocol.
+var errNoForkID = errors.New("node doesn't advertise a fork ID")
+
 // enrEntry is the ENR entry which advertises `eth` protocol on the discovery.
 type enrEntry struct {
 	ForkID forkid.ID // Fork identifier per EIP-2124
@@ -63,3 +73,99 @@ func currentENREntry(chain *core.BlockChain) *enrEntry {
 		ForkID: forkid.NewID(chain.Config(), chain.Genesis().Hash(), chain.CurrentHeader().Number.Uint64()),
 	}
 }
+
+// ProbeBootnodeForkID requests the current node record of the given bootnode
+// over discovery v4 and returns the fork identifier it advertises, allowing the
+// caller to check that the node actually serves the expected network. The probe
+// runs from a throwaway local node and gives up when ctx expires.
+func ProbeBootnodeForkID(ctx context.Context, node *enode.Node) (forkid.ID, error) {
+	if node.IP() == nil || node.UDP() == 0 {
+		return forkid.ID{}, fmt.Errorf("bootnode %v has no UDP endpoint", node.ID())
+	}
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		return forkid.ID{}, err
+	}
+	db, err := enode.OpenDB("")
+	if err != nil {
+		return forkid.ID{}, err
+	}
+	defer db.Close()
+
+	conn, err := net.ListenUDP("udp", nil)
+	if err != nil {
+		return forkid.ID{}, err
+	}
+	ln := enode.NewLocalNode(db, key)
+	ln.SetFallbackIP(net.IP{127, 0, 0, 1})
+	ln.SetFallbackUDP(conn.LocalAddr().(*net.UDPAddr).Port)
+
+	disc, err := discover.ListenV4(conn, ln, discover.Config{PrivateKey: key})
+	if err != nil {
+		conn.Close()
+		return forkid.ID{}, err
+	}
+	defer disc.Close()
+
+	type result struct {
+		node *enode.Node
+		err  error
+	}
+	resc := make(chan result, 1)
+	go func() {
+		n, err := disc.RequestENR(node)
+		resc <- result{n, err}
+	}()
+	select {
+	case <-ctx.Done():
+		return forkid.ID{}, fmt.Errorf("bootnode %v probe aborted: %w", node.ID(), ctx.Err())
+	case res := <-resc:
+		if res.err != nil {
+			return forkid.ID{}, fmt.Errorf("bootnode %v record request failed: %v", node.ID(), res.err)
+		}
+		var entry enrEntry
+		if err := res.node.Load(&entry); err != nil {
+			return forkid.ID{}, fmt.Errorf("%w: %v", errNoForkID, node.ID())
+		}
+		return entry.ForkID, nil
+	}
+}
+
+// BootnodesFromPeers converts a snapshot of healthy peers into a bootnode list,
+// e.g. to seed new nodes of a private network without public DNS trees. Only the
+// peers with a reachable endpoint are kept, each node at most once. Use
+// FilterPeersByForkID first to keep only the peers of the expected chain.
+func BootnodesFromPeers(peers []*enode.Node) []string {
+	var (
+		urls = make([]string, 0, len(peers))
+		seen = make(map[enode.ID]struct{}, len(peers))
+	)
+	for _, peer := range peers {
+		if peer == nil || peer.Incomplete() || peer.TCP() == 0 || peer.Pubkey() == nil {
+			continue
+		}
+		if _, ok := seen[peer.ID()]; ok {
+			continue
+		}
+		seen[peer.ID()] = struct{}{}
+		urls = append(urls, peer.URLv4())
+	}
+	return urls
+}
+
+// FilterPeersByForkID returns the peers advertising an `eth` fork ID accepted by
+// the given filter, dropping the ones without a fork ID in their record.
+func FilterPeersByForkID(peers []*enode.Node, filter forkid.Filter) []*enode.Node {
+	kept := make([]*enode.Node, 0, len(peers))
+	for _, peer := range peers {
+		if peer == nil {
+			continue
+		}
+		var entry enrEntry
+		if err := peer.Load(&entry); err != nil || filter(entry.ForkID) != nil {
+			continue
+		}
+		kept = append(kept, peer)
+	}
+	return kept
+}
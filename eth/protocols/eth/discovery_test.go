@@ -0,0 +1,83 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"net"
+	"testing"
+
+	"PureChain/core/forkid"
+	"PureChain/crypto"
+	"PureChain/p2p/enode"
+	"PureChain/p2p/enr"
+)
+
+// newTestPeer creates a signed node record reachable on the given TCP port,
+// advertising the given fork ID if it's non-nil.
+func newTestPeer(t *testing.T, tcp int, id *forkid.ID) *enode.Node {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var r enr.Record
+	r.Set(enr.IP(net.IP{10, 3, 58, 6}))
+	if tcp != 0 {
+		r.Set(enr.TCP(tcp))
+	}
+	if id != nil {
+		r.Set(enrEntry{ForkID: *id})
+	}
+	if err := enode.SignV4(&r, key); err != nil {
+		t.Fatal(err)
+	}
+	node, err := enode.New(enode.ValidSchemes, &r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return node
+}
+
+// Tests that peer snapshots are turned into deduplicated bootnode lists, and that
+// the fork ID filter keeps only the peers of the expected chain.
+func TestBootnodesFromPeers(t *testing.T) {
+	var (
+		ours   = forkid.ID{Hash: [4]byte{0x01}}
+		theirs = forkid.ID{Hash: [4]byte{0x02}}
+
+		good     = newTestPeer(t, 30303, &ours)
+		foreign  = newTestPeer(t, 30303, &theirs)
+		unforked = newTestPeer(t, 30303, nil)
+		noTCP    = newTestPeer(t, 0, &ours)
+	)
+	urls := BootnodesFromPeers([]*enode.Node{good, nil, good, foreign, unforked, noTCP})
+	if len(urls) != 3 || urls[0] != good.URLv4() || urls[1] != foreign.URLv4() || urls[2] != unforked.URLv4() {
+		t.Errorf("bootnode list mismatch: have %v", urls)
+	}
+	filter := func(id forkid.ID) error {
+		if id != ours {
+			return forkid.ErrLocalIncompatibleOrStale
+		}
+		return nil
+	}
+	kept := FilterPeersByForkID([]*enode.Node{good, foreign, unforked, noTCP}, filter)
+	if len(kept) != 2 || kept[0] != good || kept[1] != noTCP {
+		t.Errorf("filtered peers mismatch: have %v", kept)
+	}
+	if urls := BootnodesFromPeers(kept); len(urls) != 1 || urls[0] != good.URLv4() {
+		t.Errorf("filtered bootnode list mismatch: have %v", urls)
+	}
+}
@@ -232,6 +232,10 @@ web3._extend({
 			name: 'datadir',
 			getter: 'admin_datadir'
 		}),
+		new web3._extend.Property({
+			name: 'bootnodes',
+			getter: 'admin_bootnodes'
+		}),
 	]
 });
 `
@@ -645,6 +649,19 @@ web3._extend({
 			name: 'getHashrate',
 			call: 'miner_getHashrate'
 		}),
+		new web3._extend.Method({
+			name: 'pauseMining',
+			call: 'miner_pauseMining'
+		}),
+		new web3._extend.Method({
+			name: 'resumeMining',
+			call: 'miner_resumeMining'
+		}),
+		new web3._extend.Method({
+			name: 'setExtraData',
+			call: 'miner_setExtraData',
+			params: 1
+		}),
 	],
 	properties: []
 });
@@ -45,6 +45,7 @@ type Config struct {
 	Etherbase     common.Address `toml:",omitempty"` // Public address for block mining rewards (default = first account)
 	Notify        []string       `toml:",omitempty"` // HTTP URL list to be notified of new work packages (only useful in ethash).
 	NotifyFull    bool           `toml:",omitempty"` // Notify with pending block headers instead of work packages
+	StratumAddr   string         `toml:",omitempty"` // TCP address to serve stratum miners on (only useful in inihash).
 	ExtraData     hexutil.Bytes  `toml:",omitempty"` // Block extra data set by the miner
 	DelayLeftOver time.Duration  // Time for broadcast block
 	GasFloor      uint64         // Target gas floor for mined blocks.
@@ -53,6 +54,9 @@ type Config struct {
 	Recommit      time.Duration  // The time interval for miner to re-create mining work.
 	Noverify      bool           // Disable remote mining solution verification(only useful in ethash).
 	PosEtherbase  []common.Address
+
+	// Ratio of the parent's gas used driving the gas limit (zero = 3/2)
+	GasTarget core.GasLimitTarget `toml:",omitempty"`
 }
 
 // Miner creates blocks and searches for proof-of-work values.
@@ -175,6 +179,9 @@ func (miner *Miner) SetExtra(extra []byte) error {
 	if uint64(len(extra)) > params.MaximumExtraDataSize {
 		return fmt.Errorf("extra exceeds max length. %d > %v", len(extra), params.MaximumExtraDataSize)
 	}
+	if prefix, ok := miner.worker.chainConfig.ForbiddenExtraPrefix(extra); ok {
+		return fmt.Errorf("extra starts with reserved prefix %x", prefix)
+	}
 	miner.worker.setExtra(extra)
 	return nil
 }
@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"PureChain/common"
+	"PureChain/common/hexutil"
 	"PureChain/consensus/clique"
 	"PureChain/core"
 	"PureChain/core/rawdb"
@@ -213,6 +214,25 @@ func TestMinerSetEtherbase(t *testing.T) {
 	}
 }
 
+// Tests that extra-data starting with a prefix reserved by the chain is rejected,
+// leaving the previous value in place.
+func TestMinerSetExtra(t *testing.T) {
+	miner, _ := createMiner(t)
+	miner.worker.chainConfig.ForbiddenExtraPrefixes = []hexutil.Bytes{[]byte("pool")}
+
+	if err := miner.SetExtra([]byte("vanity")); err != nil {
+		t.Fatalf("failed to set extra-data: %v", err)
+	}
+	if err := miner.SetExtra([]byte("pool tag")); err == nil {
+		t.Error("extra-data with a reserved prefix accepted")
+	}
+	miner.worker.mu.RLock()
+	defer miner.worker.mu.RUnlock()
+	if extra := string(miner.worker.extra); extra != "vanity" {
+		t.Errorf("extra-data mismatch: have %q, want %q", extra, "vanity")
+	}
+}
+
 // waitForMiningState waits until either
 // * the desired mining state was reached
 // * a timeout was reached which fails the test
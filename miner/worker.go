@@ -77,10 +77,18 @@ const (
 
 	// staleThreshold is the maximum depth of the acceptable stale block.
 	staleThreshold = 11
+
+	// blockSizeReserve is the room left in blocks for the header fields filled in
+	// while finalizing them, when filling blocks up to the chain's size limit.
+	blockSizeReserve = 1024
 )
 
 var (
 	commitTxsTimer = metrics.NewRegisteredTimer("worker/committxs", nil)
+
+	// errLogDataLimitReached is returned by commitTransaction if the logs of the
+	// transaction would push the block over the chain's log data limit.
+	errLogDataLimitReached = errors.New("log data limit reached")
 )
 
 // environment is the worker's current environment and holds all of the current state information.
@@ -93,6 +101,8 @@ type environment struct {
 	uncles    mapset.Set     // uncle set
 	tcount    int            // tx count in cycle
 	gasPool   *core.GasPool  // available gas used to pack transactions
+	size      uint64         // encoded size of the block assembled so far
+	logData   uint64         // size of the log data emitted by the block so far
 
 	header   *types.Header
 	txs      []*types.Transaction
@@ -104,6 +114,8 @@ func (env *environment) copy() *environment {
 		signer:    env.signer,
 		state:     env.state.Copy(),
 		tcount:    env.tcount,
+		size:      env.size,
+		logData:   env.logData,
 		ancestors: env.ancestors.Clone(),
 		family:    env.family.Clone(),
 		uncles:    env.uncles.Clone(),
@@ -691,8 +703,8 @@ func (w *worker) resultLoop() {
 				}
 				logs = append(logs, receipt.Logs...)
 			}
-			// Commit block and state to database.
-			_, err := w.chain.WriteBlockWithState(block, receipts, logs, task.state, true)
+			// Validate the body and commit block and state to database.
+			_, err := w.chain.WriteLocalBlock(block, receipts, logs, task.state, true)
 			if err != nil {
 				log.Error("Failed writing block to chain", "err", err)
 				continue
@@ -729,6 +741,7 @@ func (w *worker) makeCurrent(parent *types.Block, header *types.Header) error {
 		family:    mapset.NewSet(),
 		uncles:    mapset.NewSet(),
 		header:    header,
+		size:      uint64(types.NewBlockWithHeader(header).Size()) + blockSizeReserve,
 	}
 	// Keep track of transactions which return errors so they can be removed
 	env.tcount = 0
@@ -802,8 +815,21 @@ func (w *worker) commitTransaction(tx *types.Transaction, coinbase common.Addres
 		w.current.state.RevertToSnapshot(snap)
 		return nil, err
 	}
+	// Undo the transaction if its logs would carry the block over the log data limit
+	var logData uint64
+	for _, l := range receipt.Logs {
+		logData += uint64(len(l.Data))
+	}
+	if limit := w.chainConfig.MaxLogDataBytes; limit > 0 && w.current.logData+logData > limit {
+		w.current.state.RevertToSnapshot(snap)
+		w.current.gasPool.AddGas(receipt.GasUsed)
+		w.current.header.GasUsed -= receipt.GasUsed
+		return nil, errLogDataLimitReached
+	}
+	w.current.logData += logData
 	w.current.txs = append(w.current.txs, tx)
 	w.current.receipts = append(w.current.receipts, receipt)
+	w.current.size += uint64(tx.Size())
 
 	return receipt.Logs, nil
 }
@@ -860,6 +886,11 @@ LOOP:
 			log.Trace("Not enough gas for further transactions", "have", w.current.gasPool, "want", params.TxGas)
 			break
 		}
+		// If the block holds as many transactions as the chain allows then we're done
+		if limit := w.chainConfig.MaxTxPerBlock; limit > 0 && uint64(len(w.current.txs)) >= limit {
+			log.Trace("Transaction limit reached", "txs", len(w.current.txs), "limit", limit)
+			break
+		}
 		if stopTimer != nil {
 			select {
 			case <-stopTimer.C:
@@ -890,6 +921,13 @@ LOOP:
 			txs.Pop()
 			continue
 		}
+		// Skip the account if its transaction would push the block over the size limit,
+		// smaller transactions of other accounts may still fit
+		if limit := w.chainConfig.MaxBlockBytes; limit > 0 && w.current.size+uint64(tx.Size()) > limit {
+			log.Trace("Skipping transaction exceeding the block size limit", "hash", tx.Hash(), "size", tx.Size(), "limit", limit)
+			txs.Pop()
+			continue
+		}
 		// Start executing the transaction
 		w.current.state.Prepare(tx.Hash(), common.Hash{}, w.current.tcount)
 
@@ -922,6 +960,11 @@ LOOP:
 			w.current.tcount++
 			txs.Shift()
 
+		case errors.Is(err, errLogDataLimitReached):
+			// The block can't take any more log data, stop adding transactions
+			log.Trace("Log data limit reached", "hash", tx.Hash(), "limit", w.chainConfig.MaxLogDataBytes)
+			break LOOP
+
 		case errors.Is(err, core.ErrTxTypeNotSupported):
 			// Pop the unsupported transaction without shifting in the next from the account
 			//log.Trace("Skipping unsupported transaction type", "sender", from, "type", tx.Type())
@@ -976,10 +1019,13 @@ func (w *worker) commitNewWork(interrupt *int32, noempty bool, timestamp int64)
 		header := &types.Header{
 			ParentHash: parent.Hash(),
 			Number:     num,
-			GasLimit:   core.CalcGasLimit(parent, w.config.GasFloor, w.config.GasCeil),
+			GasLimit:   core.CalcGasLimitWithTarget(parent, w.config.GasFloor, w.config.GasCeil, w.config.GasTarget),
 			Extra:      w.extra,
 			Time:       uint64(timestamp),
 		}
+		if w.chainConfig.IsLondon(num) {
+			header.GasLimit = core.CalcGasLimit1559(parent, w.config.GasCeil)
+		}
 
 		// Only set the coinbase if our consensus engine is running (avoid spurious block rewards)
 		if w.isRunning() {
@@ -353,3 +353,71 @@ func testAdjustInterval(t *testing.T, chainConfig *params.ChainConfig, engine co
 		t.Error("interval reset timeout")
 	}
 }
+
+// commitTestTransactions fills a fresh block on top of the test chain with the
+// given transactions of the bank account, returning the ones included.
+func commitTestTransactions(t *testing.T, chainConfig *params.ChainConfig, txs types.Transactions) []*types.Transaction {
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	w, b := newTestWorker(t, chainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	defer w.close()
+
+	parent := b.chain.CurrentBlock()
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number(), common.Big1),
+		GasLimit:   parent.GasLimit(),
+		Time:       parent.Time() + 1,
+		Coinbase:   testBankAddress,
+	}
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if err := w.makeCurrent(parent, header); err != nil {
+		t.Fatalf("failed to create mining context: %v", err)
+	}
+	set := types.NewTransactionsByPriceAndNonce(w.current.signer, map[common.Address]types.Transactions{testBankAddress: txs})
+	w.commitTransactions(set, testBankAddress, nil)
+	return w.current.txs
+}
+
+// Tests that transaction selection stops at the chain's transaction count limit
+// and skips transactions pushing the block over its size limit.
+func TestCommitTransactionsLimits(t *testing.T) {
+	var txs types.Transactions
+	for i := 0; i < 3; i++ {
+		tx, _ := types.SignTx(types.NewTransaction(uint64(i), testUserAddress, big.NewInt(1000), params.TxGas, nil, nil), types.HomesteadSigner{}, testBankKey)
+		txs = append(txs, tx)
+	}
+	config := *params.TestChainConfig
+	config.MaxTxPerBlock = 2
+	if included := commitTestTransactions(t, &config, txs); len(included) != 2 {
+		t.Errorf("included transactions mismatch: have %d, want 2", len(included))
+	}
+	// Leave room for a single transaction in the block
+	config = *params.TestChainConfig
+	empty := types.NewBlockWithHeader(&types.Header{Number: common.Big1, Difficulty: common.Big1})
+	config.MaxBlockBytes = uint64(empty.Size()) + blockSizeReserve + uint64(txs[0].Size()) + 64
+	if included := commitTestTransactions(t, &config, txs); len(included) != 1 {
+		t.Errorf("included transactions mismatch: have %d, want 1", len(included))
+	}
+}
+
+// Tests that transaction selection stops once the logs of the next transaction
+// would push the block over the chain's log data limit.
+func TestCommitTransactionsLogDataLimit(t *testing.T) {
+	// Each contract creation emits a single log carrying 32 bytes of data
+	logger := common.FromHex("0x60206000a000")
+
+	var txs types.Transactions
+	for i := 0; i < 3; i++ {
+		tx, _ := types.SignTx(types.NewContractCreation(uint64(i), big.NewInt(0), 100000, nil, logger), types.HomesteadSigner{}, testBankKey)
+		txs = append(txs, tx)
+	}
+	config := *params.TestChainConfig
+	config.MaxLogDataBytes = 80
+	if included := commitTestTransactions(t, &config, txs); len(included) != 2 {
+		t.Errorf("included transactions mismatch: have %d, want 2", len(included))
+	}
+}
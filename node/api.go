@@ -28,6 +28,7 @@ import (
 	"PureChain/log"
 	"PureChain/p2p"
 	"PureChain/p2p/enode"
+	"PureChain/params"
 	"PureChain/rpc"
 )
 
@@ -322,6 +323,24 @@ func (api *publicAdminAPI) NodeInfo() (*p2p.NodeInfo, error) {
 	return server.NodeInfo(), nil
 }
 
+// Bootnodes retrieves the bootnodes the node was configured with, along with the
+// version of the bootnode lists hardcoded into the binary.
+func (api *publicAdminAPI) Bootnodes() *params.BootnodeSet {
+	config := api.node.Config().P2P
+	info := &params.BootnodeSet{
+		Version:     params.BootnodesVersion,
+		Bootnodes:   make([]string, 0, len(config.BootstrapNodes)),
+		V5Bootnodes: make([]string, 0, len(config.BootstrapNodesV5)),
+	}
+	for _, node := range config.BootstrapNodes {
+		info.Bootnodes = append(info.Bootnodes, node.URLv4())
+	}
+	for _, node := range config.BootstrapNodesV5 {
+		info.V5Bootnodes = append(info.V5Bootnodes, node.String())
+	}
+	return info
+}
+
 // Datadir retrieves the current data directory the node is using.
 func (api *publicAdminAPI) Datadir() string {
 	return api.node.DataDir()
@@ -16,7 +16,16 @@
 
 package params
 
-import "PureChain/common"
+import (
+	"math/rand"
+
+	"PureChain/common"
+)
+
+// BootnodesVersion identifies the hardcoded bootnode lists and DNS node trees of
+// this release. It must be bumped whenever any of them changes, so operators can
+// tell which set a running node shipped with.
+const BootnodesVersion = "2022.1"
 
 // MainnetBootnodes are the enode URLs of the P2P bootstrap nodes running on
 // the main Ethereum network.
@@ -43,6 +52,8 @@ var DevnetBootnodes = []string{
 	"enode://5b609e290c4c2d408c36eff0f2e5cbb7bd43339c5dc27259b122449c901420fda33c83670c0a51d1f40adbe38a18ea66c097a7a0f30b619d44c050e6e7c34feb@devnet-bootnode0.inichain.com:30301",
 	"enode://b04e54ff785ddfbca7309f66283f035dcf5388959e7a92235e2b4a0d1f721da10c773c94eef60e09c69b8caab2cce402a6539eab3c2af44bb1bbb4908ad7b178@devnet-bootnode1.inichain.com:30301",
 	"enode://be431192987395f9f5455733fe806f71c4cf7ea8bc6612451433b409dd600b654921019d505887f9d9a5ac60d6612d37c7189c5117dc46a1c23f44ca634f7c3a@devnet-bootnode2.inichain.com:30301",
+	// IPv6-only devnet bootnode
+	"enode://01a656eb92ce0ae182a214b6492e472093fabc7c1e20aefd459af8cebc3ffc390b5066bffab108f64b7e3be38a844cf63bbfd3ac1e9e8540f0892f40ed8126e9@[2001:db8:1::30]:30301",
 }
 
 // RopstenBootnodes are the enode URLs of the P2P bootstrap nodes running on the
@@ -87,36 +98,197 @@ var YoloV3Bootnodes = []string{
 	"enode://9e1096aa59862a6f164994cb5cb16f5124d6c992cdbf4535ff7dea43ea1512afe5448dca9df1b7ab0726129603f1a3336b631e4d7a1a44c94daddd03241587f9@3.9.20.133:30303",
 }
 
-var V5Bootnodes = []string{
-	// Teku team's bootnode
-	"enr:-KG4QOtcP9X1FbIMOe17QNMKqDxCpm14jcX5tiOE4_TyMrFqbmhPZHK_ZPG2Gxb1GE2xdtodOfx9-cgvNtxnRyHEmC0ghGV0aDKQ9aX9QgAAAAD__________4JpZIJ2NIJpcIQDE8KdiXNlY3AyNTZrMaEDhpehBDbZjM_L9ek699Y7vhUJ-eAdMyQW_Fil522Y0fODdGNwgiMog3VkcIIjKA",
-	"enr:-KG4QDyytgmE4f7AnvW-ZaUOIi9i79qX4JwjRAiXBZCU65wOfBu-3Nb5I7b_Rmg3KCOcZM_C3y5pg7EBU5XGrcLTduQEhGV0aDKQ9aX9QgAAAAD__________4JpZIJ2NIJpcIQ2_DUbiXNlY3AyNTZrMaEDKnz_-ps3UUOfHWVYaskI5kWYO_vtYMGYCQRAR3gHDouDdGNwgiMog3VkcIIjKA",
-	// Prylab team's bootnodes
-	"enr:-Ku4QImhMc1z8yCiNJ1TyUxdcfNucje3BGwEHzodEZUan8PherEo4sF7pPHPSIB1NNuSg5fZy7qFsjmUKs2ea1Whi0EBh2F0dG5ldHOIAAAAAAAAAACEZXRoMpD1pf1CAAAAAP__________gmlkgnY0gmlwhBLf22SJc2VjcDI1NmsxoQOVphkDqal4QzPMksc5wnpuC3gvSC8AfbFOnZY_On34wIN1ZHCCIyg",
-	"enr:-Ku4QP2xDnEtUXIjzJ_DhlCRN9SN99RYQPJL92TMlSv7U5C1YnYLjwOQHgZIUXw6c-BvRg2Yc2QsZxxoS_pPRVe0yK8Bh2F0dG5ldHOIAAAAAAAAAACEZXRoMpD1pf1CAAAAAP__________gmlkgnY0gmlwhBLf22SJc2VjcDI1NmsxoQMeFF5GrS7UZpAH2Ly84aLK-TyvH-dRo0JM1i8yygH50YN1ZHCCJxA",
-	"enr:-Ku4QPp9z1W4tAO8Ber_NQierYaOStqhDqQdOPY3bB3jDgkjcbk6YrEnVYIiCBbTxuar3CzS528d2iE7TdJsrL-dEKoBh2F0dG5ldHOIAAAAAAAAAACEZXRoMpD1pf1CAAAAAP__________gmlkgnY0gmlwhBLf22SJc2VjcDI1NmsxoQMw5fqqkw2hHC4F5HZZDPsNmPdB1Gi8JPQK7pRc9XHh-oN1ZHCCKvg",
-	// Lighthouse team's bootnodes
-	"enr:-IS4QLkKqDMy_ExrpOEWa59NiClemOnor-krjp4qoeZwIw2QduPC-q7Kz4u1IOWf3DDbdxqQIgC4fejavBOuUPy-HE4BgmlkgnY0gmlwhCLzAHqJc2VjcDI1NmsxoQLQSJfEAHZApkm5edTCZ_4qps_1k_ub2CxHFxi-gr2JMIN1ZHCCIyg",
-	"enr:-IS4QDAyibHCzYZmIYZCjXwU9BqpotWmv2BsFlIq1V31BwDDMJPFEbox1ijT5c2Ou3kvieOKejxuaCqIcjxBjJ_3j_cBgmlkgnY0gmlwhAMaHiCJc2VjcDI1NmsxoQJIdpj_foZ02MXz4It8xKD7yUHTBx7lVFn3oeRP21KRV4N1ZHCCIyg",
-	// EF bootnodes
-	"enr:-Ku4QHqVeJ8PPICcWk1vSn_XcSkjOkNiTg6Fmii5j6vUQgvzMc9L1goFnLKgXqBJspJjIsB91LTOleFmyWWrFVATGngBh2F0dG5ldHOIAAAAAAAAAACEZXRoMpC1MD8qAAAAAP__________gmlkgnY0gmlwhAMRHkWJc2VjcDI1NmsxoQKLVXFOhp2uX6jeT0DvvDpPcU8FWMjQdR4wMuORMhpX24N1ZHCCIyg",
-	"enr:-Ku4QG-2_Md3sZIAUebGYT6g0SMskIml77l6yR-M_JXc-UdNHCmHQeOiMLbylPejyJsdAPsTHJyjJB2sYGDLe0dn8uYBh2F0dG5ldHOIAAAAAAAAAACEZXRoMpC1MD8qAAAAAP__________gmlkgnY0gmlwhBLY-NyJc2VjcDI1NmsxoQORcM6e19T1T9gi7jxEZjk_sjVLGFscUNqAY9obgZaxbIN1ZHCCIyg",
-	"enr:-Ku4QPn5eVhcoF1opaFEvg1b6JNFD2rqVkHQ8HApOKK61OIcIXD127bKWgAtbwI7pnxx6cDyk_nI88TrZKQaGMZj0q0Bh2F0dG5ldHOIAAAAAAAAAACEZXRoMpC1MD8qAAAAAP__________gmlkgnY0gmlwhDayLMaJc2VjcDI1NmsxoQK2sBOLGcUb4AwuYzFuAVCaNHA-dy24UuEKkeFNgCVCsIN1ZHCCIyg",
-	"enr:-Ku4QEWzdnVtXc2Q0ZVigfCGggOVB2Vc1ZCPEc6j21NIFLODSJbvNaef1g4PxhPwl_3kax86YPheFUSLXPRs98vvYsoBh2F0dG5ldHOIAAAAAAAAAACEZXRoMpC1MD8qAAAAAP__________gmlkgnY0gmlwhDZBrP2Jc2VjcDI1NmsxoQM6jr8Rb1ktLEsVcKAPa08wCsKUmvoQ8khiOl_SLozf9IN1ZHCCIyg",
+// BootnodeSet describes the bootnodes a node bootstraps from.
+type BootnodeSet struct {
+	Version     string   `json:"version"`           // Version of the hardcoded lists, see BootnodesVersion
+	Network     string   `json:"network,omitempty"` // Name of the network the lists belong to, if known
+	Bootnodes   []string `json:"bootnodes"`         // Enode URLs of the discovery v4 bootnodes
+	V5Bootnodes []string `json:"v5Bootnodes"`       // Node URLs of the discovery v5 bootnodes
+}
+
+// BootnodeInfo returns the hardcoded bootnodes of the network with the given
+// genesis hash, tagged with BootnodesVersion, or nil if the network is unknown.
+func BootnodeInfo(genesis common.Hash) *BootnodeSet {
+	bootnodes := bootnodesFor(genesis)
+	if bootnodes == nil {
+		return nil
+	}
+	return &BootnodeSet{
+		Version:     BootnodesVersion,
+		Network:     networkName(genesis),
+		Bootnodes:   append([]string(nil), bootnodes...),
+		V5Bootnodes: []string{}, // No discovery v5 bootnodes are hardcoded
+	}
+}
+
+// BootnodesShuffled returns a copy of the bootnodes of the network with the given
+// genesis hash, shuffled deterministically by the seed, or nil if the network is
+// unknown. Seeding with the node's identity spreads the load of the nodes dialing
+// their list in order across the bootnodes, while the order of a single node
+// stays stable across restarts. The ordered lists are left untouched.
+func BootnodesShuffled(genesis common.Hash, seed int64) []string {
+	bootnodes := bootnodesFor(genesis)
+	if bootnodes == nil {
+		return nil
+	}
+	shuffled := append([]string(nil), bootnodes...)
+	rand.New(rand.NewSource(seed)).Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+	return shuffled
 }
 
-const dnsPrefix = "enrtree://AKA3AM6LPBYEUDMVNU3BSVQJ5AD45Y7YPOHJLEF6W26QOE4VTUDPE@"
+// bootnodesFor returns the hardcoded bootnodes of the network with the given
+// genesis hash, or nil if the network is unknown.
+func bootnodesFor(genesis common.Hash) []string {
+	switch genesis {
+	case MainnetGenesisHash:
+		return MainnetBootnodes
+	case TestnetGenesisHash:
+		return TestnetBootnodes
+	case DevnetGenesisHash:
+		return DevnetBootnodes
+	default:
+		return nil
+	}
+}
+
+// networkName returns the name of the network with the given genesis hash, or an
+// empty string if the network is unknown.
+func networkName(genesis common.Hash) string {
+	switch genesis {
+	case MainnetGenesisHash:
+		return "mainnet"
+	case TestnetGenesisHash:
+		return "testnet"
+	case DevnetGenesisHash:
+		return "devnet"
+	default:
+		return ""
+	}
+}
+
+// Public keys signing the DNS node trees of each network.
+const (
+	mainnetDNSKey = "ANAEZPQQQ5GOAFG5SFIGURW7KCLRGVEXXPEGY6QCC4OY7OKZQYH4G"
+	testnetDNSKey = "AKYPBHXLPDMRHPR2A3TRK442F4RVGOP7U4OHM7IWBHKJKARIG5JDE"
+	devnetDNSKey  = "AJATMG4VYABHAD7CRJESQ3A4TU6T3V4JLEDR5KT2H6OCVZ5O6YZX6"
+)
+
+// DNSTree is a DNS node tree advertising the peers of a network.
+type DNSTree struct {
+	PublicKey string // Public key signing the tree
+	Domain    string // Domain serving the tree, prefixed by the protocol name
+}
+
+// DNSTreePublicKey returns the public key signing the primary DNS node tree of the
+// given network, e.g. to verify the records served for it.
+func DNSTreePublicKey(network string) (string, bool) {
+	known, ok := knownNetworks[network]
+	if !ok || len(known.network.DNSTrees) == 0 {
+		return "", false
+	}
+	return known.network.DNSTrees[0].PublicKey, true
+}
 
 // KnownDNSNetwork returns the address of a public DNS-based node list for the given
-// genesis hash and protocol. See https://github.com/ethereum/discv4-dns-lists for more
-// information.
+// genesis hash and protocol. Only the primary tree is returned, see KnownDNSNetworks for all.
 func KnownDNSNetwork(genesis common.Hash, protocol string) string {
-	var net string
+	urls := KnownDNSNetworks(genesis, protocol)
+	if len(urls) == 0 {
+		return ""
+	}
+	return urls[0]
+}
+
+// KnownDNSNetworks returns the addresses of all public DNS-based node lists for the
+// given genesis hash and protocol, primary first, or nil if the network is unknown.
+func KnownDNSNetworks(genesis common.Hash, protocol string) []string {
+	config := NetworkConfigFor(genesis)
+	if config == nil {
+		return nil
+	}
+	return config.DNSNetworks(protocol)
+}
+
+// NetworkConfig bundles the discovery settings of a network: the genesis it
+// starts from, its bootnodes and the DNS node trees advertising its peers.
+type NetworkConfig struct {
+	GenesisHash common.Hash // Genesis block hash identifying the network (empty if unknown)
+	Bootnodes   []string    // Enode URLs of the P2P bootstrap nodes
+
+	// DNS node trees advertising the peers of the network, primary first. Rotating
+	// the signing key appends a tree under the new key and promotes it once the old
+	// tree is retired, so nodes in the field keep trusting both meanwhile.
+	DNSTrees []DNSTree
+}
+
+var (
+	// MainnetNetworkConfig contains the discovery settings of the main network.
+	MainnetNetworkConfig = &NetworkConfig{
+		GenesisHash: MainnetGenesisHash,
+		Bootnodes:   MainnetBootnodes,
+		DNSTrees:    []DNSTree{{PublicKey: mainnetDNSKey, Domain: "nodes.genesis-mainnet.inichain.com"}},
+	}
+
+	// TestnetNetworkConfig contains the discovery settings of the test network.
+	TestnetNetworkConfig = &NetworkConfig{
+		GenesisHash: TestnetGenesisHash,
+		Bootnodes:   TestnetBootnodes,
+		DNSTrees:    []DNSTree{{PublicKey: testnetDNSKey, Domain: "nodes.genesis-testnet.inichain.com"}},
+	}
+
+	// DevnetNetworkConfig contains the discovery settings of the development network.
+	DevnetNetworkConfig = &NetworkConfig{
+		GenesisHash: DevnetGenesisHash,
+		Bootnodes:   DevnetBootnodes,
+		DNSTrees:    []DNSTree{{PublicKey: devnetDNSKey, Domain: "nodes.devnet.inichain.com"}},
+	}
+)
+
+// NetworkConfigFor returns the discovery settings of the network with the given
+// genesis hash, or nil if the network is unknown.
+func NetworkConfigFor(genesis common.Hash) *NetworkConfig {
 	switch genesis {
 	case MainnetGenesisHash:
-		net = "mainnet"
+		return MainnetNetworkConfig
+	case TestnetGenesisHash:
+		return TestnetNetworkConfig
+	case DevnetGenesisHash:
+		return DevnetNetworkConfig
 	default:
+		return nil
+	}
+}
+
+// NewTestNetworkConfig creates the discovery settings of a custom network
+// bootstrapped from the given nodes, without DNS discovery.
+func NewTestNetworkConfig(genesis common.Hash, bootnodes []string) *NetworkConfig {
+	return &NetworkConfig{
+		GenesisHash: genesis,
+		Bootnodes:   append([]string(nil), bootnodes...),
+	}
+}
+
+// DNSNetwork returns the address of the primary DNS-based node list of the network
+// for the given protocol, or an empty string if the network has none.
+func (c *NetworkConfig) DNSNetwork(protocol string) string {
+	urls := c.DNSNetworks(protocol)
+	if len(urls) == 0 {
 		return ""
 	}
-	return dnsPrefix + protocol + "." + net + ".ethdisco.net"
+	return urls[0]
+}
+
+// DNSNetworks returns the addresses of all DNS-based node lists of the network for
+// the given protocol, primary first, or nil if the network has none.
+func (c *NetworkConfig) DNSNetworks(protocol string) []string {
+	if len(c.DNSTrees) == 0 {
+		return nil
+	}
+	urls := make([]string, 0, len(c.DNSTrees))
+	for _, tree := range c.DNSTrees {
+		urls = append(urls, "enrtree://"+tree.PublicKey+"@"+protocol+"."+tree.Domain)
+	}
+	return urls
 }
@@ -0,0 +1,212 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package params
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"PureChain/crypto"
+	"PureChain/p2p/enode"
+	"PureChain/p2p/enr"
+)
+
+// LoadBootnodes reads a list of bootnode enode URLs from a JSON file holding an
+// array of strings, allowing operators to override the compiled-in bootnodes of
+// a network at startup. Every entry must be a valid v4 enode URL.
+func LoadBootnodes(path string) ([]string, error) {
+	blob, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var urls []string
+	if err := json.Unmarshal(blob, &urls); err != nil {
+		return nil, fmt.Errorf("invalid bootnode file %s: %v", path, err)
+	}
+	for i, url := range urls {
+		if _, err := enode.ParseV4(url); err != nil {
+			return nil, fmt.Errorf("invalid bootnode %d (%q) in %s: %w", i, url, path, err)
+		}
+	}
+	return urls, nil
+}
+
+// ValidateBootnodes checks that every entry is a well formed v4 enode URL and
+// returns the list without the entries repeating the node ID of an earlier one.
+// Malformed entries are reported together in a single error. Host names are
+// checked but not resolved, so the validation works offline.
+func ValidateBootnodes(urls []string) ([]string, error) {
+	var (
+		valid = make([]string, 0, len(urls))
+		seen  = make(map[enode.ID]bool)
+		errs  []string
+	)
+	for i, url := range urls {
+		id, err := parseBootnode(url)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("entry %d (%q): %v", i, url, err))
+			continue
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		valid = append(valid, url)
+	}
+	if len(errs) > 0 {
+		return valid, fmt.Errorf("invalid bootnodes: %s", strings.Join(errs, "; "))
+	}
+	return valid, nil
+}
+
+// parseBootnode checks the format of a v4 enode URL like enode.ParseV4 does, but
+// without resolving host names. It returns the node ID.
+func parseBootnode(rawurl string) (enode.ID, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return enode.ID{}, err
+	}
+	if u.Scheme != "enode" {
+		return enode.ID{}, errors.New("invalid URL scheme, want \"enode\"")
+	}
+	if u.User == nil {
+		return enode.ID{}, errors.New("does not contain node ID")
+	}
+	blob, err := hex.DecodeString(u.User.String())
+	if err != nil || len(blob) != 64 {
+		return enode.ID{}, errors.New("invalid public key")
+	}
+	key, err := crypto.UnmarshalPubkey(append([]byte{0x04}, blob...))
+	if err != nil {
+		return enode.ID{}, fmt.Errorf("invalid public key (%v)", err)
+	}
+	if u.Hostname() == "" {
+		return enode.ID{}, errors.New("missing host")
+	}
+	if port, err := strconv.ParseUint(u.Port(), 10, 16); err != nil || port == 0 {
+		return enode.ID{}, errors.New("invalid port")
+	}
+	return enode.PubkeyToIDV4(key), nil
+}
+
+// LocalBootnode returns a loopback enode URL listening on the given port, backed
+// by a freshly generated node key. It's meant to bootstrap isolated local test
+// clusters without relying on any public bootnodes.
+func LocalBootnode(port int) (string, error) {
+	return LocalBootnodeWithKey(port, "")
+}
+
+// LocalBootnodeWithKey is like LocalBootnode, but persists the node key in the
+// given file. If the file already holds a key it is reused, keeping the enode
+// URL stable across restarts.
+func LocalBootnodeWithKey(port int, keyfile string) (string, error) {
+	if port <= 0 || port > 65535 {
+		return "", fmt.Errorf("invalid bootnode port %d", port)
+	}
+	key, err := loadOrGenerateNodeKey(keyfile)
+	if err != nil {
+		return "", err
+	}
+	return enode.NewV4(&key.PublicKey, net.IPv4(127, 0, 0, 1), port, port).URLv4(), nil
+}
+
+// loadOrGenerateNodeKey loads the node key from the given file, generating and
+// saving a new one if it doesn't exist yet. An empty path yields an ephemeral key.
+func loadOrGenerateNodeKey(keyfile string) (*ecdsa.PrivateKey, error) {
+	if keyfile == "" {
+		return crypto.GenerateKey()
+	}
+	if _, err := os.Stat(keyfile); err == nil {
+		key, err := crypto.LoadECDSA(keyfile)
+		if err != nil {
+			return nil, fmt.Errorf("invalid node key %s: %v", keyfile, err)
+		}
+		return key, nil
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	if err := crypto.SaveECDSA(keyfile, key); err != nil {
+		return nil, fmt.Errorf("failed to persist node key: %v", err)
+	}
+	return key, nil
+}
+
+// EnodeToENR converts a v4 enode URL into a textual ENR usable by discovery v5.
+// Node records must be signed by the node itself, so the conversion requires the
+// node's private key, which has to match the public key in the URL.
+func EnodeToENR(url string, key *ecdsa.PrivateKey) (string, error) {
+	node, err := enode.ParseV4(url)
+	if err != nil {
+		return "", fmt.Errorf("invalid enode %q: %v", url, err)
+	}
+	if key == nil {
+		return "", errors.New("node key required to sign record")
+	}
+	if pub := node.Pubkey(); pub == nil || !pub.Equal(&key.PublicKey) {
+		return "", fmt.Errorf("node key doesn't match enode %q", url)
+	}
+	var r enr.Record
+	if ip := node.IP(); ip != nil {
+		r.Set(enr.IP(ip))
+	}
+	if node.TCP() != 0 {
+		r.Set(enr.TCP(node.TCP()))
+	}
+	if node.UDP() != 0 {
+		r.Set(enr.UDP(node.UDP()))
+	}
+	if err := enode.SignV4(&r, key); err != nil {
+		return "", err
+	}
+	signed, err := enode.New(enode.ValidSchemes, &r)
+	if err != nil {
+		return "", err
+	}
+	return signed.String(), nil
+}
+
+// ENRToEnode converts a textual ENR into a v4 enode URL usable by discovery v4.
+// The record must carry a secp256k1 public key and an IP endpoint.
+func ENRToEnode(record string) (string, error) {
+	node, err := enode.Parse(enode.ValidSchemes, record)
+	if err != nil {
+		return "", fmt.Errorf("invalid node record %q: %v", record, err)
+	}
+	if node.Pubkey() == nil {
+		return "", fmt.Errorf("node record %q has no secp256k1 public key", record)
+	}
+	if node.Incomplete() {
+		return "", fmt.Errorf("node record %q has no IP endpoint", record)
+	}
+	if node.TCP() == 0 && node.UDP() == 0 {
+		return "", fmt.Errorf("node record %q has no ports", record)
+	}
+	return node.URLv4(), nil
+}
@@ -0,0 +1,324 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package params
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"PureChain/common"
+	"PureChain/crypto"
+	"PureChain/p2p/enode"
+	"PureChain/p2p/enr"
+)
+
+// Tests that local bootnodes are valid loopback enodes and that persisting the
+// node key keeps the URL stable across invocations.
+func TestLocalBootnode(t *testing.T) {
+	url, err := LocalBootnode(30301)
+	if err != nil {
+		t.Fatalf("failed to create local bootnode: %v", err)
+	}
+	node, err := enode.ParseV4(url)
+	if err != nil {
+		t.Fatalf("invalid local bootnode %q: %v", url, err)
+	}
+	if !node.IP().IsLoopback() || node.TCP() != 30301 {
+		t.Errorf("endpoint mismatch: have %v:%d, want loopback:30301", node.IP(), node.TCP())
+	}
+	dir, err := ioutil.TempDir("", "bootnode-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	keyfile := filepath.Join(dir, "nodekey")
+	first, err := LocalBootnodeWithKey(30301, keyfile)
+	if err != nil {
+		t.Fatalf("failed to create persistent bootnode: %v", err)
+	}
+	second, err := LocalBootnodeWithKey(30301, keyfile)
+	if err != nil {
+		t.Fatalf("failed to reload persistent bootnode: %v", err)
+	}
+	if first != second {
+		t.Errorf("persistent bootnode changed: have %s, want %s", second, first)
+	}
+	if _, err := LocalBootnode(0); err == nil {
+		t.Error("expected error for invalid port")
+	}
+}
+
+// Tests that enode URLs and node records convert into each other.
+func TestENRConversion(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	url := enode.NewV4(&key.PublicKey, net.ParseIP("10.3.58.6"), 30303, 30301).URLv4()
+
+	record, err := EnodeToENR(url, key)
+	if err != nil {
+		t.Fatalf("failed to convert enode to record: %v", err)
+	}
+	if !strings.HasPrefix(record, "enr:") {
+		t.Fatalf("invalid record %q", record)
+	}
+	back, err := ENRToEnode(record)
+	if err != nil {
+		t.Fatalf("failed to convert record to enode: %v", err)
+	}
+	if back != url {
+		t.Errorf("round trip mismatch: have %s, want %s", back, url)
+	}
+	other, _ := crypto.GenerateKey()
+	if _, err := EnodeToENR(url, other); err == nil {
+		t.Error("expected error for mismatching node key")
+	}
+	// Records without an endpoint can't be expressed as complete enodes
+	var r enr.Record
+	if err := enode.SignV4(&r, key); err != nil {
+		t.Fatal(err)
+	}
+	bare, _ := enode.New(enode.ValidSchemes, &r)
+	if _, err := ENRToEnode(bare.String()); err == nil {
+		t.Error("expected error for record without endpoint")
+	}
+}
+
+// Tests that bootnode lists are loaded from JSON files, rejecting invalid entries.
+func TestLoadBootnodes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bootnodes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	key, _ := crypto.GenerateKey()
+	valid := enode.NewV4(&key.PublicKey, net.ParseIP("10.3.58.6"), 30303, 30303).URLv4()
+
+	good := filepath.Join(dir, "good.json")
+	ioutil.WriteFile(good, []byte(`["`+valid+`"]`), 0600)
+	urls, err := LoadBootnodes(good)
+	if err != nil {
+		t.Fatalf("failed to load bootnodes: %v", err)
+	}
+	if len(urls) != 1 || urls[0] != valid {
+		t.Errorf("bootnode mismatch: have %v, want [%s]", urls, valid)
+	}
+	bad := filepath.Join(dir, "bad.json")
+	ioutil.WriteFile(bad, []byte(`["`+valid+`", "enode://foo"]`), 0600)
+	if _, err := LoadBootnodes(bad); err == nil || !strings.Contains(err.Error(), "bootnode 1") {
+		t.Errorf("expected error naming the invalid entry, got %v", err)
+	}
+}
+
+// Tests that bootnodes with IPv6 literal hosts survive parsing and loading.
+func TestIPv6Bootnodes(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	url := enode.NewV4(&key.PublicKey, net.ParseIP("2001:db8::1"), 30303, 30301).URLv4()
+	if !strings.Contains(url, "@[2001:db8::1]:30303") {
+		t.Fatalf("unexpected IPv6 enode URL: %s", url)
+	}
+	node, err := enode.ParseV4(url)
+	if err != nil {
+		t.Fatalf("failed to parse IPv6 enode: %v", err)
+	}
+	if !node.IP().Equal(net.ParseIP("2001:db8::1")) || node.TCP() != 30303 || node.UDP() != 30301 {
+		t.Errorf("endpoint mismatch: have %v:%d/%d", node.IP(), node.TCP(), node.UDP())
+	}
+	if have := node.URLv4(); have != url {
+		t.Errorf("round trip mismatch: have %s, want %s", have, url)
+	}
+	// Make sure the compiled in IPv6 devnet bootnode parses with its IPv6 endpoint
+	var found bool
+	for _, url := range DevnetBootnodes {
+		if !strings.Contains(url, "@[") {
+			continue
+		}
+		found = true
+		node, err := enode.ParseV4(url)
+		if err != nil {
+			t.Errorf("invalid IPv6 devnet bootnode %s: %v", url, err)
+		} else if node.IP().To4() != nil || node.IP().To16() == nil {
+			t.Errorf("devnet bootnode %s has no IPv6 endpoint: %v", url, node.IP())
+		}
+	}
+	if !found {
+		t.Error("no IPv6 devnet bootnode")
+	}
+	// Make sure the validator and the file loader accept them too
+	if valid, err := ValidateBootnodes([]string{url}); err != nil || len(valid) != 1 {
+		t.Errorf("failed to validate IPv6 bootnode: %v %v", valid, err)
+	}
+	dir, err := ioutil.TempDir("", "bootnodes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "ipv6.json")
+	ioutil.WriteFile(path, []byte(`["`+url+`"]`), 0600)
+	if urls, err := LoadBootnodes(path); err != nil || len(urls) != 1 || urls[0] != url {
+		t.Errorf("failed to load IPv6 bootnode: %v %v", urls, err)
+	}
+}
+
+// Tests that the compiled in bootnode lists are well formed and free of duplicates.
+func TestHardcodedBootnodes(t *testing.T) {
+	for name, urls := range map[string][]string{
+		"mainnet": MainnetBootnodes,
+		"testnet": TestnetBootnodes,
+		"devnet":  DevnetBootnodes,
+	} {
+		valid, err := ValidateBootnodes(urls)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		if len(valid) != len(urls) {
+			t.Errorf("%s: %d duplicate bootnodes", name, len(urls)-len(valid))
+		}
+	}
+}
+
+func TestValidateBootnodes(t *testing.T) {
+	key1, _ := crypto.GenerateKey()
+	key2, _ := crypto.GenerateKey()
+	var (
+		node1 = enode.NewV4(&key1.PublicKey, net.ParseIP("10.3.58.6"), 30303, 30303).URLv4()
+		node2 = enode.NewV4(&key2.PublicKey, net.ParseIP("10.3.58.7"), 30303, 30303).URLv4()
+		moved = enode.NewV4(&key1.PublicKey, net.ParseIP("10.3.58.8"), 30303, 30303).URLv4()
+		share = enode.NewV4(&key2.PublicKey, net.ParseIP("10.3.58.6"), 30303, 30303).URLv4()
+	)
+	valid, err := ValidateBootnodes([]string{node1, node2, node1, moved})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(valid) != 2 || valid[0] != node1 || valid[1] != node2 {
+		t.Errorf("deduplicated list mismatch: have %v", valid)
+	}
+	// Distinct nodes behind the same endpoint are both kept
+	if valid, err := ValidateBootnodes([]string{node1, share}); err != nil || len(valid) != 2 {
+		t.Errorf("nodes sharing an endpoint mismatch: have %v, %v", valid, err)
+	}
+	// Malformed entries must all be reported
+	_, err = ValidateBootnodes([]string{node1, "enode://foo@1.2.3.4:30303", strings.Replace(node2, ":30303", ":0", 1)})
+	if err == nil {
+		t.Fatal("expected error for malformed bootnodes")
+	}
+	for _, want := range []string{"entry 1", "entry 2"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q doesn't mention %s", err, want)
+		}
+	}
+}
+
+// Tests that all DNS node trees of a network are returned during a key rotation,
+// while the primary one is still reported on its own.
+func TestKnownDNSNetworks(t *testing.T) {
+	if urls := KnownDNSNetworks(common.Hash{1}, "all"); urls != nil {
+		t.Errorf("unknown network has DNS trees: %v", urls)
+	}
+	// Every network is served by its own tree under its own signing key
+	for genesis, want := range map[common.Hash]string{
+		TestnetGenesisHash: "enrtree://" + testnetDNSKey + "@all.nodes.genesis-testnet.inichain.com",
+		DevnetGenesisHash:  "enrtree://" + devnetDNSKey + "@all.nodes.devnet.inichain.com",
+	} {
+		if url := KnownDNSNetwork(genesis, "all"); url != want {
+			t.Errorf("tree mismatch: have %s, want %s", url, want)
+		}
+	}
+	if mainnetDNSKey == testnetDNSKey || mainnetDNSKey == devnetDNSKey || testnetDNSKey == devnetDNSKey {
+		t.Error("networks share a DNS tree signing key")
+	}
+	primary := "enrtree://" + mainnetDNSKey + "@all.nodes.genesis-mainnet.inichain.com"
+	if url := KnownDNSNetwork(MainnetGenesisHash, "all"); url != primary {
+		t.Errorf("primary tree mismatch: have %s, want %s", url, primary)
+	}
+	if url := MainnetNetworkConfig.DNSNetwork("all"); url != primary {
+		t.Errorf("network config tree mismatch: have %s, want %s", url, primary)
+	}
+	defer func(trees []DNSTree) { MainnetNetworkConfig.DNSTrees = trees }(MainnetNetworkConfig.DNSTrees)
+	MainnetNetworkConfig.DNSTrees = append(MainnetNetworkConfig.DNSTrees, DNSTree{PublicKey: "NEWKEY", Domain: "mainnet.example.org"})
+
+	urls := KnownDNSNetworks(MainnetGenesisHash, "les")
+	want := []string{"enrtree://" + mainnetDNSKey + "@les.nodes.genesis-mainnet.inichain.com", "enrtree://NEWKEY@les.mainnet.example.org"}
+	if strings.Join(urls, ",") != strings.Join(want, ",") {
+		t.Errorf("trees mismatch: have %v, want %v", urls, want)
+	}
+	if url := KnownDNSNetwork(MainnetGenesisHash, "les"); url != want[0] {
+		t.Errorf("primary tree mismatch after rotation: have %s, want %s", url, want[0])
+	}
+}
+
+// Tests that the bootnode info of the known networks carries their lists and the
+// version they ship with.
+func TestBootnodeInfo(t *testing.T) {
+	if info := BootnodeInfo(common.Hash{1}); info != nil {
+		t.Errorf("unknown network has bootnode info: %+v", info)
+	}
+	info := BootnodeInfo(DevnetGenesisHash)
+	if info == nil {
+		t.Fatal("no bootnode info for devnet")
+	}
+	if info.Version != BootnodesVersion || info.Network != "devnet" {
+		t.Errorf("info mismatch: have version %q network %q, want %q devnet", info.Version, info.Network, BootnodesVersion)
+	}
+	if strings.Join(info.Bootnodes, ",") != strings.Join(DevnetBootnodes, ",") {
+		t.Errorf("bootnodes mismatch: have %v, want %v", info.Bootnodes, DevnetBootnodes)
+	}
+	// Mutating the returned lists must not affect the hardcoded ones
+	info.Bootnodes[0] = ""
+	if DevnetBootnodes[0] == "" {
+		t.Error("hardcoded bootnodes modified through the info")
+	}
+}
+
+// Tests that shuffled bootnodes are a deterministic permutation of the ordered
+// list, leaving the list itself intact.
+func TestBootnodesShuffled(t *testing.T) {
+	if urls := BootnodesShuffled(common.Hash{1}, 1); urls != nil {
+		t.Errorf("unknown network has bootnodes: %v", urls)
+	}
+	ordered := append([]string(nil), MainnetBootnodes...)
+
+	first := BootnodesShuffled(MainnetGenesisHash, 42)
+	if second := BootnodesShuffled(MainnetGenesisHash, 42); strings.Join(first, ",") != strings.Join(second, ",") {
+		t.Errorf("shuffle not deterministic: %v != %v", first, second)
+	}
+	if strings.Join(MainnetBootnodes, ",") != strings.Join(ordered, ",") {
+		t.Errorf("ordered list modified: have %v, want %v", MainnetBootnodes, ordered)
+	}
+	have, want := append([]string(nil), first...), append([]string(nil), ordered...)
+	sort.Strings(have)
+	sort.Strings(want)
+	if strings.Join(have, ",") != strings.Join(want, ",") {
+		t.Errorf("shuffle not a permutation: have %v, want %v", first, ordered)
+	}
+	// Different identities should eventually dial a different bootnode first
+	for seed := int64(0); seed < 100; seed++ {
+		if BootnodesShuffled(MainnetGenesisHash, seed)[0] != first[0] {
+			return
+		}
+	}
+	t.Errorf("all seeds dial %s first", first[0])
+}
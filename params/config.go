@@ -17,11 +17,13 @@
 package params
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"math/big"
 
 	"PureChain/common"
+	"PureChain/common/hexutil"
 	"golang.org/x/crypto/sha3"
 )
 
@@ -68,7 +70,7 @@ var (
 		BerlinBlock:         big.NewInt(4),
 		RedCoastBlock:       big.NewInt(22222222220),
 		//Ethash:              new(EthashConfig),
-		Inihash: &InihashConfig{},
+		Inihash: &InihashConfig{TargetBlockTime: 30},
 	}
 
 	TestnetChainConfig = &ChainConfig{
@@ -91,7 +93,7 @@ var (
 		BerlinBlock:         big.NewInt(4),
 		RedCoastBlock:       big.NewInt(22222222220),
 		//Ethash:              new(EthashConfig),
-		Inihash: &InihashConfig{},
+		Inihash: &InihashConfig{TargetBlockTime: 30},
 	}
 	DevnetChainConfig = &ChainConfig{
 		ChainID:        big.NewInt(7235),
@@ -113,7 +115,7 @@ var (
 		BerlinBlock:         big.NewInt(4),
 		RedCoastBlock:       big.NewInt(22222222220),
 		//Ethash:              new(EthashConfig),
-		Inihash: &InihashConfig{},
+		Inihash: &InihashConfig{TargetBlockTime: 30},
 	}
 
 	// MainnetTrustedCheckpoint contains the light client trusted checkpoint for the main network.
@@ -277,6 +279,7 @@ var (
 		RamanujanBlock:      big.NewInt(0),
 		NielsBlock:          big.NewInt(0),
 		MirrorSyncBlock:     big.NewInt(5184000),
+		SystemTxGas:         true,
 		Parlia: &ParliaConfig{
 			Period: 3,
 			Epoch:  200,
@@ -297,6 +300,7 @@ var (
 		RamanujanBlock:      big.NewInt(1010000),
 		NielsBlock:          big.NewInt(1014369),
 		MirrorSyncBlock:     big.NewInt(5582500),
+		SystemTxGas:         true,
 		Parlia: &ParliaConfig{
 			Period: 3,
 			Epoch:  200,
@@ -317,6 +321,7 @@ var (
 		RamanujanBlock:      big.NewInt(400),
 		NielsBlock:          big.NewInt(0),
 		MirrorSyncBlock:     big.NewInt(400),
+		SystemTxGas:         true,
 		Parlia: &ParliaConfig{
 			Period: 3,
 			Epoch:  200,
@@ -353,16 +358,16 @@ var (
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllEthashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, new(InihashConfig), nil, nil, nil}
+	AllEthashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, big.NewInt(0), nil, nil, nil, big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, 0, 0, false, 0, 0, 0, 0, 0, 0, nil, nil, false, nil, new(InihashConfig), nil, nil, nil}
 
 	// AllCliqueProtocolChanges contains every protocol change (EIPs) introduced
 	// and accepted by the Ethereum core developers into the Clique consensus.
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, &CliqueConfig{Period: 0, Epoch: 30000}, nil, nil}
+	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, big.NewInt(0), nil, nil, nil, big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, 0, 0, false, 0, 0, 0, 0, 0, 0, nil, nil, false, nil, nil, &CliqueConfig{Period: 0, Epoch: 30000}, nil, nil}
 
-	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, new(InihashConfig), nil, nil, nil}
+	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, big.NewInt(0), nil, nil, nil, big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, 0, 0, false, 0, 0, 0, 0, 0, 0, nil, nil, false, nil, new(InihashConfig), nil, nil, nil}
 
 	TestRules = TestChainConfig.Rules(new(big.Int))
 )
@@ -447,11 +452,29 @@ type ChainConfig struct {
 	CatalystBlock *big.Int `json:"catalystBlock,omitempty"` // Catalyst switch block (nil = no fork, 0 = already on catalyst)
 
 	RedCoastBlock *big.Int `json:"redCoastBlock,omitempty"` // RedCoast switch block (nil = no fork, 0 = already activated)
+	LondonBlock   *big.Int `json:"londonBlock,omitempty"`   // London switch block (nil = no fork, 0 = already activated)
+	ShanghaiBlock *big.Int `json:"shanghaiBlock,omitempty"` // Shanghai switch block (nil = no fork, 0 = already activated)
+	EIP3607Block  *big.Int `json:"eip3607Block,omitempty"`  // EIP3607 switch block, rejecting senders with code (nil = no fork, 0 = already activated)
 
 	RamanujanBlock  *big.Int `json:"ramanujanBlock,omitempty" toml:",omitempty"`  // ramanujanBlock switch block (nil = no fork, 0 = already activated)
 	NielsBlock      *big.Int `json:"nielsBlock,omitempty" toml:",omitempty"`      // nielsBlock switch block (nil = no fork, 0 = already activated)
 	MirrorSyncBlock *big.Int `json:"mirrorSyncBlock,omitempty" toml:",omitempty"` // mirrorSyncBlock switch block (nil = no fork, 0 = already activated)
 
+	// Optional block validation rules, skipped when left empty
+	ForbiddenExtraPrefixes []hexutil.Bytes  `json:"forbiddenExtraPrefixes,omitempty" toml:",omitempty"` // Extra-data prefixes reserved for official miner tags
+	BlockSlotPeriod        uint64           `json:"blockSlotPeriod,omitempty" toml:",omitempty"`        // Seconds between block slots counted from genesis (0 = no slot schedule)
+	BlockSlotTolerance     uint64           `json:"blockSlotTolerance,omitempty" toml:",omitempty"`     // Seconds a block timestamp may deviate from its slot boundary
+	CoinbaseIsSealer       bool             `json:"coinbaseIsSealer,omitempty" toml:",omitempty"`       // Whether the coinbase must equal the sealer recovered by the engine
+	MaxLogDataBytes        uint64           `json:"maxLogDataBytes,omitempty" toml:",omitempty"`        // Maximum total size of the log data emitted by a block (0 = unlimited)
+	MaxBlockTimeGap        uint64           `json:"maxBlockTimeGap,omitempty" toml:",omitempty"`        // Maximum seconds between a block and its parent (0 = unlimited)
+	MaxTxPerBlock          uint64           `json:"maxTxPerBlock,omitempty" toml:",omitempty"`          // Maximum number of transactions in a block (0 = unlimited)
+	AllowedFutureDrift     uint64           `json:"allowedFutureDrift,omitempty" toml:",omitempty"`     // Seconds a block timestamp may be ahead of the local clock (0 = unchecked)
+	MaxBlockBytes          uint64           `json:"maxBlockBytes,omitempty" toml:",omitempty"`          // Maximum RLP encoded size of a block (0 = unlimited)
+	MaxReorgDepth          uint64           `json:"maxReorgDepth,omitempty" toml:",omitempty"`          // Maximum number of blocks a reorg may rewrite above the common ancestor (0 = unlimited)
+	AuthorizedSealers      []common.Address `json:"authorizedSealers,omitempty" toml:",omitempty"`      // Coinbases allowed to seal blocks (empty = anyone)
+	NonZeroCoinbaseBlock   *big.Int         `json:"nonZeroCoinbaseBlock,omitempty" toml:",omitempty"`   // Block from which proof-of-work blocks may not pay the zero coinbase (nil = never)
+	SystemTxGas            bool             `json:"systemTxGas,omitempty" toml:",omitempty"`            // Whether the engine may charge gas for system calls in blocks without transactions
+
 	// Various consensus engines
 	Ethash  *EthashConfig  `json:"ethash,omitempty" toml:",omitempty"`
 	Inihash *InihashConfig `json:"inihash,omitempty" toml:",omitempty"`
@@ -463,7 +486,18 @@ type ChainConfig struct {
 
 // EthashConfig is the consensus engine configs for proof-of-work based sealing.
 type EthashConfig struct{}
-type InihashConfig struct{}
+
+// InihashConfig is the consensus engine configs for inihash proof-of-work based
+// sealing.
+type InihashConfig struct {
+	TargetBlockTime   uint64 `json:"targetBlockTime,omitempty"`   // Number of seconds between blocks the difficulty adjustment aims for
+	UncleRewardBudget uint64 `json:"uncleRewardBudget,omitempty"` // Maximum uncle rewards per block, in units of the largest single uncle reward (0 = unchecked)
+
+	// Difficulty retargeting, zero values keep the mainnet behavior
+	DifficultyWindow       uint64 `json:"difficultyWindow,omitempty"`       // Seconds of block time deviation per difficulty adjustment step (0 = 5)
+	DifficultyBoundDivisor uint64 `json:"difficultyBoundDivisor,omitempty"` // Divisor of the parent difficulty giving the size of a step (0 = 12288)
+	MinimumDifficulty      uint64 `json:"minimumDifficulty,omitempty"`      // Floor the difficulty never drops below (0 = MinimumDifficulty)
+}
 
 // String implements the stringer interface, returning the consensus engine details.
 func (c *EthashConfig) String() string {
@@ -527,7 +561,7 @@ func (c *ChainConfig) String() string {
 	default:
 		engine = "unknown"
 	}
-	return fmt.Sprintf("{ChainID: %v Homestead: %v DAO: %v DAOSupport: %v EIP150: %v EIP155: %v EIP158: %v Byzantium: %v Constantinople: %v Petersburg: %v Istanbul: %v, Muir Glacier: %v, Ramanujan: %v, Niels: %v, MirrorSync: %v, Berlin: %v, YOLO v3: %v,RedCoast: %v, Engine: %v}",
+	return fmt.Sprintf("{ChainID: %v Homestead: %v DAO: %v DAOSupport: %v EIP150: %v EIP155: %v EIP158: %v Byzantium: %v Constantinople: %v Petersburg: %v Istanbul: %v, Muir Glacier: %v, Ramanujan: %v, Niels: %v, MirrorSync: %v, Berlin: %v, YOLO v3: %v,RedCoast: %v, London: %v, Shanghai: %v, EIP3607: %v, Engine: %v}",
 		c.ChainID,
 		c.HomesteadBlock,
 		c.DAOForkBlock,
@@ -546,6 +580,9 @@ func (c *ChainConfig) String() string {
 		c.BerlinBlock,
 		c.YoloV3Block,
 		c.RedCoastBlock,
+		c.LondonBlock,
+		c.ShanghaiBlock,
+		c.EIP3607Block,
 		engine,
 	)
 }
@@ -642,6 +679,38 @@ func (c *ChainConfig) IsRedCoast(num *big.Int) bool {
 	return isForked(c.RedCoastBlock, num)
 }
 
+// IsLondon returns whether num is either equal to the London fork block or greater.
+func (c *ChainConfig) IsLondon(num *big.Int) bool {
+	return isForked(c.LondonBlock, num)
+}
+
+// IsShanghai returns whether num is either equal to the Shanghai fork block or greater.
+func (c *ChainConfig) IsShanghai(num *big.Int) bool {
+	return isForked(c.ShanghaiBlock, num)
+}
+
+// IsEIP3607 returns whether num is either equal to the EIP3607 fork block or greater.
+func (c *ChainConfig) IsEIP3607(num *big.Int) bool {
+	return isForked(c.EIP3607Block, num)
+}
+
+// IsNonZeroCoinbase returns whether num is either equal to the block from which
+// proof-of-work blocks may not pay the zero coinbase or greater.
+func (c *ChainConfig) IsNonZeroCoinbase(num *big.Int) bool {
+	return isForked(c.NonZeroCoinbaseBlock, num)
+}
+
+// ForbiddenExtraPrefix returns the reserved prefix the given extra-data starts
+// with, or false if it doesn't start with any of ForbiddenExtraPrefixes.
+func (c *ChainConfig) ForbiddenExtraPrefix(extra []byte) ([]byte, bool) {
+	for _, prefix := range c.ForbiddenExtraPrefixes {
+		if len(prefix) > 0 && bytes.HasPrefix(extra, prefix) {
+			return prefix, true
+		}
+	}
+	return nil, false
+}
+
 // IsCatalyst returns whether num is either equal to the Merge fork block or greater.
 func (c *ChainConfig) IsCatalyst(num *big.Int) bool {
 	return isForked(c.CatalystBlock, num)
@@ -760,6 +829,18 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, head *big.Int) *Confi
 	if isForkIncompatible(c.MirrorSyncBlock, newcfg.MirrorSyncBlock, head) {
 		return newCompatError("mirrorSync fork block", c.MirrorSyncBlock, newcfg.MirrorSyncBlock)
 	}
+	if isForkIncompatible(c.LondonBlock, newcfg.LondonBlock, head) {
+		return newCompatError("London fork block", c.LondonBlock, newcfg.LondonBlock)
+	}
+	if isForkIncompatible(c.ShanghaiBlock, newcfg.ShanghaiBlock, head) {
+		return newCompatError("Shanghai fork block", c.ShanghaiBlock, newcfg.ShanghaiBlock)
+	}
+	if isForkIncompatible(c.EIP3607Block, newcfg.EIP3607Block, head) {
+		return newCompatError("EIP3607 fork block", c.EIP3607Block, newcfg.EIP3607Block)
+	}
+	if isForkIncompatible(c.NonZeroCoinbaseBlock, newcfg.NonZeroCoinbaseBlock, head) {
+		return newCompatError("non-zero coinbase block", c.NonZeroCoinbaseBlock, newcfg.NonZeroCoinbaseBlock)
+	}
 	return nil
 }
 
@@ -827,7 +908,7 @@ type Rules struct {
 	ChainID                                                 *big.Int
 	IsHomestead, IsEIP150, IsEIP155, IsEIP158               bool
 	IsByzantium, IsConstantinople, IsPetersburg, IsIstanbul bool
-	IsBerlin, IsCatalyst, IsRedCoast                        bool
+	IsBerlin, IsCatalyst, IsRedCoast, IsShanghai            bool
 }
 
 // Rules ensures c's ChainID is not nil.
@@ -849,5 +930,6 @@ func (c *ChainConfig) Rules(num *big.Int) Rules {
 		IsBerlin:         c.IsBerlin(num),
 		IsCatalyst:       c.IsCatalyst(num),
 		IsRedCoast:       c.IsRedCoast(num),
+		IsShanghai:       c.IsShanghai(num),
 	}
 }
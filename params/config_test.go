@@ -17,6 +17,7 @@
 package params
 
 import (
+	"encoding/json"
 	"math/big"
 	"reflect"
 	"testing"
@@ -96,3 +97,44 @@ func TestCheckCompatible(t *testing.T) {
 		}
 	}
 }
+
+// Tests that the network descriptor carries its version and the fork schedule as
+// an explicit list rather than the chain configuration.
+func TestNetworkParamsJSON(t *testing.T) {
+	if _, err := NetworkParamsJSON("unknown"); err == nil {
+		t.Fatal("expected error for unknown network")
+	}
+	blob, err := NetworkParamsJSON("mainnet")
+	if err != nil {
+		t.Fatalf("failed to describe mainnet: %v", err)
+	}
+	var desc map[string]json.RawMessage
+	if err := json.Unmarshal(blob, &desc); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if _, ok := desc["config"]; ok {
+		t.Error("descriptor embeds the chain config")
+	}
+	var params networkParams
+	if err := json.Unmarshal(blob, &params); err != nil {
+		t.Fatalf("failed to decode descriptor: %v", err)
+	}
+	if params.Version != NetworkParamsVersion {
+		t.Errorf("version mismatch: have %d, want %d", params.Version, NetworkParamsVersion)
+	}
+	if params.ChainID.Cmp(MainnetChainConfig.ChainID) != 0 {
+		t.Errorf("chain ID mismatch: have %v, want %v", params.ChainID, MainnetChainConfig.ChainID)
+	}
+	var berlin *big.Int
+	for _, fork := range params.Forks {
+		if fork.Block == nil {
+			t.Errorf("unscheduled fork %s listed", fork.Name)
+		}
+		if fork.Name == "berlinBlock" {
+			berlin = fork.Block
+		}
+	}
+	if berlin == nil || berlin.Cmp(MainnetChainConfig.BerlinBlock) != 0 {
+		t.Errorf("berlin fork mismatch: have %v, want %v", berlin, MainnetChainConfig.BerlinBlock)
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package params
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"PureChain/common"
+)
+
+// knownNetwork pairs the chain configuration of a public network with its
+// discovery settings.
+type knownNetwork struct {
+	chain   *ChainConfig
+	network *NetworkConfig
+}
+
+// knownNetworks are the public networks, keyed by name.
+var knownNetworks = map[string]knownNetwork{
+	"mainnet": {MainnetChainConfig, MainnetNetworkConfig},
+	"testnet": {TestnetChainConfig, TestnetNetworkConfig},
+	"devnet":  {DevnetChainConfig, DevnetNetworkConfig},
+}
+
+// GasLimitsFor returns the gas floor and ceil miners aim for on the network with
+// the given genesis hash. Unknown networks get the main network's limits.
+func GasLimitsFor(genesis common.Hash) (floor, ceil uint64) {
+	switch genesis {
+	case TestnetGenesisHash:
+		return TestnetGasFloor, TestnetGasCeil
+	case DevnetGenesisHash:
+		return DevnetGasFloor, DevnetGasCeil
+	default:
+		return MainnetGasFloor, MainnetGasCeil
+	}
+}
+
+// NetworkParamsVersion is the version of the JSON documents produced by
+// NetworkParamsJSON. It's bumped whenever a field changes meaning or is removed,
+// adding fields keeps the version.
+const NetworkParamsVersion = 1
+
+// networkParams is the JSON descriptor of a network produced by NetworkParamsJSON.
+// It lists the consumable parameters explicitly instead of embedding the chain
+// configuration, so internal configuration changes don't leak into the output.
+type networkParams struct {
+	Version     int           `json:"version"`
+	Name        string        `json:"name"`
+	ChainID     *big.Int      `json:"chainId"`
+	GenesisHash *common.Hash  `json:"genesisHash,omitempty"`
+	Bootnodes   []string      `json:"bootnodes"`
+	DNSTreeKey  string        `json:"dnsTreeKey,omitempty"`
+	DNSDomain   string        `json:"dnsDomain,omitempty"`
+	Forks       []networkFork `json:"forks"`
+}
+
+// networkFork is a scheduled fork of a network, named after its chain config field.
+type networkFork struct {
+	Name  string   `json:"name"`
+	Block *big.Int `json:"block"`
+}
+
+// networkForks returns the forks scheduled by the chain configuration, in the
+// order they're defined in. Forks not scheduled are left out.
+func networkForks(c *ChainConfig) []networkFork {
+	forks := make([]networkFork, 0)
+	for _, fork := range []networkFork{
+		{"homesteadBlock", c.HomesteadBlock},
+		{"daoForkBlock", c.DAOForkBlock},
+		{"eip150Block", c.EIP150Block},
+		{"eip155Block", c.EIP155Block},
+		{"eip158Block", c.EIP158Block},
+		{"byzantiumBlock", c.ByzantiumBlock},
+		{"constantinopleBlock", c.ConstantinopleBlock},
+		{"petersburgBlock", c.PetersburgBlock},
+		{"istanbulBlock", c.IstanbulBlock},
+		{"muirGlacierBlock", c.MuirGlacierBlock},
+		{"berlinBlock", c.BerlinBlock},
+		{"ramanujanBlock", c.RamanujanBlock},
+		{"nielsBlock", c.NielsBlock},
+		{"mirrorSyncBlock", c.MirrorSyncBlock},
+		{"redCoastBlock", c.RedCoastBlock},
+		{"londonBlock", c.LondonBlock},
+		{"shanghaiBlock", c.ShanghaiBlock},
+		{"eip3607Block", c.EIP3607Block},
+		{"nonZeroCoinbaseBlock", c.NonZeroCoinbaseBlock},
+	} {
+		if fork.Block != nil {
+			forks = append(forks, fork)
+		}
+	}
+	return forks
+}
+
+// NetworkParamsJSON returns a JSON document describing the given public network:
+// its chain ID, genesis hash, bootnodes, DNS node tree and fork schedule. The
+// document carries NetworkParamsVersion.
+func NetworkParamsJSON(network string) ([]byte, error) {
+	known, ok := knownNetworks[network]
+	if !ok {
+		return nil, fmt.Errorf("unknown network %q", network)
+	}
+	desc := &networkParams{
+		Version:   NetworkParamsVersion,
+		Name:      network,
+		ChainID:   known.chain.ChainID,
+		Bootnodes: known.network.Bootnodes,
+		Forks:     networkForks(known.chain),
+	}
+	if trees := known.network.DNSTrees; len(trees) > 0 {
+		desc.DNSTreeKey, desc.DNSDomain = trees[0].PublicKey, trees[0].Domain
+	}
+	if known.network.GenesisHash != (common.Hash{}) {
+		hash := known.network.GenesisHash
+		desc.GenesisHash = &hash
+	}
+	return json.MarshalIndent(desc, "", "  ")
+}
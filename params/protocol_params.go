@@ -21,8 +21,17 @@ import "math/big"
 const (
 	GasLimitBoundDivisor uint64 = 256     // The bound divisor of the gas limit, used in update calculations.
 	MinGasLimit          uint64 = 5000    // Minimum the gas limit may ever be.
+	GasTargetNumerator   uint64 = 3       // Numerator of the parent's gas used ratio contributing to the next gas limit (2/3 target utilization).
+	GasTargetDenominator uint64 = 2       // Denominator of the parent's gas used ratio contributing to the next gas limit.
 	GenesisGasLimit      uint64 = 4712388 // Gas limit of the Genesis block.
 
+	MainnetGasFloor uint64 = 15000000 // Gas limit miners of the main network aim for at least.
+	MainnetGasCeil  uint64 = 30000000 // Gas limit miners of the main network aim for at most.
+	TestnetGasFloor uint64 = 15000000 // Gas limit miners of the test network aim for at least.
+	TestnetGasCeil  uint64 = 30000000 // Gas limit miners of the test network aim for at most.
+	DevnetGasFloor  uint64 = 15000000 // Gas limit miners of the development network aim for at least.
+	DevnetGasCeil   uint64 = 30000000 // Gas limit miners of the development network aim for at most.
+
 	MaximumExtraDataSize  uint64 = 32     // Maximum size extra data may be after Genesis.
 	ForkIDSize            uint64 = 4      // The length of fork id
 	ExpByteGas            uint64 = 10     // Times ceil(log256(exponent)) for the EXP instruction.
@@ -110,7 +119,8 @@ const (
 	// Introduced in Tangerine Whistle (Eip 150)
 	CreateBySelfdestructGas uint64 = 25000
 
-	MaxCodeSize = 24576 // Maximum bytecode to permit for a contract
+	MaxCodeSize     = 24576           // Maximum bytecode to permit for a contract
+	MaxInitCodeSize = 2 * MaxCodeSize // Maximum initcode to permit in a creation transaction and create instructions
 
 	// Precompiled contract gas prices
 